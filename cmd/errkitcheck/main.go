@@ -0,0 +1,13 @@
+// Command errkitcheck runs the errkit migration analyzer.
+// It can be used standalone, with `-fix` to apply the suggested fixes, or as `go vet -vettool`.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"mig/pkg/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -1,32 +1,234 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 
+	"mig/pkg/checker"
+	"mig/pkg/config"
+	"mig/pkg/journal"
 	"mig/pkg/migrator"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/report"
 	"mig/pkg/traverser"
 )
 
+// Exit codes
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitNeedsManual = 2
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "undo" {
+		os.Exit(runUndo(os.Args[2:]))
+	}
+
+	var flags flagValues
+	dryRun := flag.Bool("dry-run", false, "do not modify files, print unified diff of the changes instead")
+	flag.StringVar(&flags.reportPath, "report", "", "write JSON report of every file and call site to the given file")
+	flag.StringVar(&flags.sarifPath, "sarif", "", "write SARIF 2.1.0 log of the call sites to be migrated manually to the given file")
+	workers := flag.Int("workers", 0, "number of files processed in parallel, the number of CPUs by default")
+	verify := flag.Bool("verify", false, "type-check the packages of the migrated files and revert files which introduce new type errors")
+	check := flag.Bool("check", false, "do not modify files, report remaining github.com/pkg/errors usage and TODO markers instead")
+	baselinePath := flag.String("baseline", "", "in check mode, ignore findings accepted by the given baseline file")
+	updateBaseline := flag.Bool("update-baseline", false, "in check mode, write all findings to the baseline file")
+	flag.BoolVar(&flags.noTodo, "no-todo", false, "do not add TODO comments to the code to be migrated manually")
+	flag.StringVar(&flags.todo, "todo", common.DefaultConfig.TodoComment, "comment marking the code to be migrated manually")
+	flag.StringVar(&flags.rulesPath, "rules", "", "load rewrite rules from the given YAML file, they take precedence over the built-in ones (v2 and v3 engines only)")
+	flag.StringVar(&flags.engine, "engine", string(migrator.V2), "migrator version: v1, v2 or v3")
+	flag.StringVar(&flags.source, "source", common.DefaultPackages.Source, "import path of the package to migrate from")
+	flag.StringVar(&flags.target, "target", common.DefaultPackages.Target, "import path of the package to migrate to")
+	flag.StringVar(&flags.qualifier, "qualifier", "", "identifier the migrated code refers to the target package by, the package name by default")
+	flag.StringVar(&flags.include, "include", "", "comma separated globs of the files to be migrated, relative to the path")
+	flag.StringVar(&flags.exclude, "exclude", "", "comma separated globs of the files and directories to be skipped, relative to the path")
+	flag.BoolVar(&flags.backup, "backup", false, "keep the original content of every changed file next to it with the "+traverser.BackupSuffix+" suffix")
+	flag.StringVar(&flags.backupDir, "backup-dir", "", "save the original content of every changed file under the given directory, keeping the paths relative to the migrated path")
+	flag.StringVar(&flags.journalDir, "journal", "", "directory the run journals are kept in, to undo the runs with `migr undo`, the user cache directory by default")
+	flag.BoolVar(&flags.noJournal, "no-journal", false, "do not record the run journal")
+	flag.BoolVar(&flags.all, "all", false, "also migrate hidden, vendor, testdata, git-ignored and generated files")
+	flag.StringVar(&flags.configPath, "config", "", "project configuration file, "+config.FileName+" found in the path or its parents by default")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: myapp [flags] <path>")
+		fmt.Fprintln(flag.CommandLine.Output(), "       myapp undo [-journal dir] [run-id]")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "Flags override the settings of the project configuration file.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Exit code is %d if some files failed to be processed, "+
+			"%d if some code has to be migrated manually, some files are reverted by verification or, "+
+			"in check mode, some code is not migrated yet.\n", exitFailure, exitNeedsManual)
+	}
+	flag.Parse()
+
 	// Check if a path is provided in the command line arguments
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: myapp <path>")
-		return
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(exitFailure)
 	}
 
 	// Get the path from the command line arguments
-	path := os.Args[1]
+	path := flag.Arg(0)
 
-	matchers, err := migrator.GetMigratorHandlers(migrator.V2)
+	s, err := loadSettings(path, flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitFailure)
+	}
+
+	if *check {
+		os.Exit(runCheck(path, s, *baselinePath, *updateBaseline))
+	}
+
+	matchers, err := migrator.GetMigratorHandlers(s.engine, s.cfg)
 	if err != nil {
 		fmt.Println(err)
-		return
+		os.Exit(exitFailure)
+	}
+
+	opts := traverser.Options{
+		DryRun:    *dryRun,
+		Verify:    *verify,
+		Filter:    s.filter,
+		Workers:   *workers,
+		Backup:    s.backup,
+		BackupDir: s.backupDir,
+	}
+
+	var recorder *journal.Recorder
+	if !*dryRun && s.journalDir != "" {
+		recorder, err = journal.Begin(s.journalDir, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start the journal: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		opts.Journal = recorder
 	}
 
 	// Pass the command line path to the TraverseAndModifyFiles function
-	traverser.TraverseAndModifyFiles(
-		path,
-		matchers,
-	)
+	results, err := traverser.TraverseAndModifyFiles(path, matchers, opts)
+
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the journal: %v\n", err)
+		}
+		if recorder.Len() > 0 {
+			fmt.Printf("Run %s changed %d files, undo it with: migr undo %s\n", recorder.ID(), recorder.Len(), recorder.ID())
+		}
+	}
+
+	if err != nil {
+		os.Exit(exitFailure)
+	}
+
+	if s.reportPath != "" {
+		if err := writeReport(s.reportPath, path, results, report.WriteJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	}
+
+	if s.sarifPath != "" {
+		if err := writeReport(s.sarifPath, path, results, report.WriteSARIF); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write SARIF log: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	}
+
+	os.Exit(exitCode(results))
+}
+
+// writeReport writes report of the results of the root traversal to the file in the format of the writer.
+func writeReport(path, root string, results []traverser.FileResult, write func(io.Writer, report.Report) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, report.New(root, results)); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// runCheck reports the code which is not migrated yet and returns the exit code.
+func runCheck(path string, s settings, baselinePath string, updateBaseline bool) int {
+	findings, err := checker.Check(path, s.filter, s.cfg.Packages, s.cfg.TodoComment)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	switch {
+	case updateBaseline && baselinePath == "":
+		fmt.Fprintln(os.Stderr, "-update-baseline requires -baseline")
+		return exitFailure
+	case updateBaseline:
+		if err := writeBaseline(baselinePath, findings); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write baseline: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	case baselinePath != "":
+		baseline, err := readBaseline(baselinePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read baseline: %v\n", err)
+			return exitFailure
+		}
+		findings = baseline.Filter(findings)
+	}
+
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+
+	if len(findings) > 0 {
+		return exitNeedsManual
+	}
+
+	return exitOK
+}
+
+// readBaseline reads the baseline from the file.
+func readBaseline(path string) (checker.Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return checker.Baseline{}, err
+	}
+	defer file.Close()
+
+	return checker.ReadBaseline(file)
+}
+
+// writeBaseline writes the findings to the baseline file.
+func writeBaseline(path string, findings []checker.Finding) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := checker.WriteBaseline(file, findings); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// exitCode returns the exit code reflecting the most severe outcome of the files processing.
+func exitCode(results []traverser.FileResult) int {
+	code := exitOK
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			return exitFailure
+		case r.Reverted || r.Status == common.NeedsManual:
+			code = exitNeedsManual
+		}
+	}
+
+	return code
 }
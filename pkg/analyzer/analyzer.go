@@ -0,0 +1,318 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"mig/pkg/internal/edit"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/imports"
+	"mig/pkg/migrator/matcher_v2/mutators"
+	"mig/pkg/migrator/resolver"
+)
+
+// Analyzer reports invocations of `github.com/pkg/errors` functions and suggests errkit replacements.
+//
+// Every invocation the handlers can migrate carries a fix replacing it. Invocations nested into another
+// fixed invocation get no fix of their own, as the edits would overlap; they are reported again once
+// the outer fix is applied. Invocations with comments inside get no fix, as the comments can't be carried over,
+// otherwise the line breaks between the arguments are kept. Every fix also fixes the imports the same way
+// the traverser does, so the file compiles once the fix is applied on its own.
+//
+// The packages migrated between are configured by the -source, -target and -qualifier flags,
+// the same way the migration configures them.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errkit",
+	Doc:      "report github.com/pkg/errors usage and suggest errkit replacements",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// packages are the packages migrated between, set by the flags of the analyzer
+var packages common.Packages
+
+func init() {
+	Analyzer.Flags.StringVar(&packages.Source, "source", common.DefaultPackages.Source, "import path of the package being migrated from")
+	Analyzer.Flags.StringVar(&packages.Target, "target", common.DefaultPackages.Target, "import path of the package being migrated to")
+	Analyzer.Flags.StringVar(&packages.Qualifier, "qualifier", "",
+		"identifier the migrated invocations refer to the target package by, the name of the target package if empty")
+}
+
+// migration holds the packages and the handlers configured for the analyzer run.
+type migration struct {
+	pkgs      common.Packages
+	handlers  mutators.HandlerMap
+	sentinels mutators.HandlerMap // used for the invocations declaring sentinel errors
+}
+
+// newMigration returns the migration between the packages.
+func newMigration(pkgs common.Packages) migration {
+	pkgs = pkgs.WithDefaults()
+	opts := mutators.Options{Qualifier: pkgs.Qualifier}
+	handlers := mutators.Handlers(opts)
+
+	return migration{
+		pkgs:      pkgs,
+		handlers:  handlers,
+		sentinels: mutators.WithSentinelHandlers(handlers, mutators.Sentinels(opts)),
+	}
+}
+
+// sourceName returns the name of the source package used in the messages.
+func (m migration) sourceName() string {
+	return resolver.PackageName(m.pkgs.Source)
+}
+
+// call is an invocation of `github.com/pkg/errors` function.
+type call struct {
+	expr      *ast.CallExpr
+	funcName  string
+	rewritten string // empty if the invocation can't be migrated
+	fixed     bool   // whether the fix is suggested for the invocation
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	m := newMigration(packages)
+
+	calls := map[*ast.File][]*call{}
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		expr := n.(*ast.CallExpr)
+		if sel, ok := isErrorsCall(pass.TypesInfo, expr, m.pkgs.Source); ok {
+			file := stack[0].(*ast.File)
+			calls[file] = append(calls[file], &call{expr: expr, funcName: sel.Sel.Name})
+		}
+
+		return true
+	})
+
+	for _, file := range pass.Files {
+		spec, name := resolver.FindImport(file, m.pkgs.Source)
+		if spec == nil {
+			continue
+		}
+
+		src, err := readFile(pass, file)
+		if err != nil {
+			return nil, err
+		}
+
+		fileCalls := calls[file]
+		sentinels := resolver.FindSentinels(file, name)
+		var fixed []*call
+		for _, c := range fileCalls {
+			// Variadic invocations can't be split into separate parameters, and the comments between
+			// the arguments can't be carried over, so such invocations are left to be migrated manually
+			if c.expr.Ellipsis.IsValid() || resolver.ContainsComments(file, c.expr) {
+				continue
+			}
+
+			handlers := m.handlers
+			if sentinels[c.expr] != "" {
+				handlers = m.sentinels
+			}
+
+			original := normalize(pass.Fset, src, c)
+			if rewritten := mutators.Mutator(original, handlers); rewritten != original {
+				c.rewritten = relayout(original, rewritten)
+				// Calls are visited in the order of appearance, so outer calls are always seen first
+				c.fixed = !nested(c, fixed)
+			}
+			if c.fixed {
+				fixed = append(fixed, c)
+			}
+		}
+
+		pass.Report(analysis.Diagnostic{Pos: spec.Pos(), End: spec.End(), Message: m.pkgs.Source + " is imported"})
+		m.reportCalls(pass, file, src, fileCalls)
+	}
+
+	return nil, nil
+}
+
+// reportCalls reports the invocations, suggesting fixes for those which can be migrated.
+// Every fix carries the edit of the import declarations too, so the file compiles once any single fix is applied.
+func (m migration) reportCalls(pass *analysis.Pass, file *ast.File, src []byte, calls []*call) {
+	for _, c := range calls {
+		diagnostic := analysis.Diagnostic{
+			Pos:     c.expr.Pos(),
+			End:     c.expr.End(),
+			Message: m.sourceName() + "." + c.funcName + " has to be migrated to " + m.pkgs.Qualifier + " manually",
+		}
+
+		if c.rewritten != "" {
+			diagnostic.Message = m.sourceName() + "." + c.funcName + " can be migrated to " + m.pkgs.Qualifier
+		}
+
+		if c.fixed {
+			edits := []analysis.TextEdit{{Pos: c.expr.Pos(), End: c.expr.End(), NewText: []byte(c.rewritten)}}
+			if importEdit := m.importEdit(pass.Fset, file, src, c); importEdit != nil {
+				edits = append(edits, *importEdit)
+			}
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{{Message: "Replace with " + oneLine(c.rewritten), TextEdits: edits}}
+		}
+
+		pass.Report(diagnostic)
+	}
+}
+
+// importEdit returns the edit replacing the import declarations of the file with the ones imports.Fix produces
+// for the source with the fix of the invocation applied, or nil if the imports are fine as they are.
+// The way the traverser fixes them, errkit replaces the import if no other invocation is left, otherwise errkit
+// is imported next to it, and `fmt` is imported if the fix refers to it. The edits of the fixes keeping the import
+// are the same, so the drivers applying several fixes at once merge them, which may leave the import unused
+// for goimports to remove.
+func (m migration) importEdit(fset *token.FileSet, file *ast.File, src []byte, c *call) *analysis.TextEdit {
+	migrated := applyFixes(fset, src, []*call{c})
+	fixed, err := imports.Fix(src, migrated, m.pkgs)
+	if err != nil || bytes.Equal(fixed, migrated) {
+		// The fix of the invocation is suggested anyway
+		return nil
+	}
+
+	fixedFset := token.NewFileSet()
+	fixedFile, err := parser.ParseFile(fixedFset, "", fixed, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	start, end := importLines(fset, file, len(src))
+	fixedStart, fixedEnd := importLines(fixedFset, fixedFile, len(fixed))
+
+	tokFile := fset.File(file.Pos())
+	return &analysis.TextEdit{Pos: tokFile.Pos(start), End: tokFile.Pos(end), NewText: fixed[fixedStart:fixedEnd]}
+}
+
+// applyFixes returns the source with the fixed invocations replaced.
+func applyFixes(fset *token.FileSet, src []byte, calls []*call) []byte {
+	var edits []edit.Edit
+	for _, c := range calls {
+		if c.fixed {
+			edits = append(edits, edit.Edit{Start: offset(fset, c.expr.Pos()), End: offset(fset, c.expr.End()), Text: c.rewritten})
+		}
+	}
+
+	return edit.Apply(src, edits)
+}
+
+// importLines returns the offsets of the start of the first line and the end of the last line
+// holding the import declarations of the file, so the comments following them are included.
+func importLines(fset *token.FileSet, file *ast.File, size int) (int, int) {
+	var first, last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			if first == nil {
+				first = gen
+			}
+			last = gen
+		}
+	}
+
+	tokFile := fset.File(file.Pos())
+	if first == nil {
+		// Imports are put right after the package clause
+		end := offset(fset, lineAfter(fset, file.Name.End()))
+		return end, end
+	}
+
+	start := tokFile.Offset(tokFile.LineStart(tokFile.Line(first.Pos())))
+	end := size
+	if line := tokFile.Line(last.End()); line < tokFile.LineCount() {
+		end = tokFile.Offset(tokFile.LineStart(line + 1))
+	}
+
+	return start, end
+}
+
+// normalize returns the source of the invocation referring to the package as `errors`, as the handlers expect.
+func normalize(fset *token.FileSet, src []byte, c *call) string {
+	return "errors." + c.funcName + string(src[offset(fset, c.expr.Lparen):offset(fset, c.expr.End())])
+}
+
+// oneLine returns the invocation spanning multiple lines joined into a single line for the messages.
+func oneLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimLeft(lines[i], " \t")
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// relayout returns the rewritten invocation keeping the line breaks between the original arguments.
+func relayout(original, rewritten string) string {
+	if !strings.Contains(original, "\n") {
+		return rewritten
+	}
+
+	args, gaps, err := mutators.CallGaps(original)
+	if err != nil {
+		return rewritten
+	}
+
+	return mutators.Relayout(rewritten, args, gaps)
+}
+
+// isErrorsCall reports whether the call is an invocation of the function of the package imported by the source path.
+func isErrorsCall(info *types.Info, expr *ast.CallExpr, source string) (*ast.SelectorExpr, bool) {
+	sel, ok := expr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+
+	pkgName, ok := info.Uses[ident].(*types.PkgName)
+	if !ok || pkgName.Imported().Path() != source {
+		return nil, false
+	}
+
+	return sel, true
+}
+
+// nested reports whether the call is located within one of the given calls.
+func nested(c *call, calls []*call) bool {
+	for _, outer := range calls {
+		if outer.expr.Pos() <= c.expr.Pos() && c.expr.End() <= outer.expr.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readFile returns the source of the file the way the driver provides it, so the unsaved changes
+// of the file the AST is parsed from are seen too.
+func readFile(pass *analysis.Pass, file *ast.File) ([]byte, error) {
+	return pass.ReadFile(pass.Fset.File(file.Pos()).Name())
+}
+
+// lineAfter returns the start of the line following the one containing pos.
+func lineAfter(fset *token.FileSet, pos token.Pos) token.Pos {
+	tokFile := fset.File(pos)
+	line := tokFile.Line(pos)
+	if line == tokFile.LineCount() {
+		return token.Pos(tokFile.Base() + tokFile.Size())
+	}
+
+	return tokFile.LineStart(line + 1)
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.File(pos).Offset(pos)
+}
@@ -0,0 +1,29 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"mig/pkg/analyzer"
+	common "mig/pkg/migrator/common"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), analyzer.Analyzer, "a", "b", "c", "d", "f")
+}
+
+func TestAnalyzerPackages(t *testing.T) {
+	flags := map[string]string{"source": "example.com/errors", "target": "example.com/errkit", "qualifier": "xerrkit"}
+	for name, value := range flags {
+		assert.NoError(t, analyzer.Analyzer.Flags.Set(name, value))
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, analyzer.Analyzer.Flags.Set("source", common.DefaultPackages.Source))
+		assert.NoError(t, analyzer.Analyzer.Flags.Set("target", common.DefaultPackages.Target))
+		assert.NoError(t, analyzer.Analyzer.Flags.Set("qualifier", ""))
+	})
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), analyzer.Analyzer, "e")
+}
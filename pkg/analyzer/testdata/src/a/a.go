@@ -0,0 +1,18 @@
+package a
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors" // want `github.com/pkg/errors is imported`
+)
+
+var ErrNotFound = errors.New("not found")
+
+func foo(err error, pvcName string) error {
+	if errors.Is(err, ErrNotFound) {
+		return /* want `errors.Wrapf can be migrated to errkit` */ pkgerrors.Wrapf(err,
+			"Failed to get PVC %s",
+			pvcName)
+	}
+	return pkgerrors.Wrap(pkgerrors.New("inner"), "outer") // want `errors.Wrap can be migrated to errkit` `errors.New can be migrated to errkit`
+}
@@ -0,0 +1,10 @@
+package b
+
+import "github.com/pkg/errors" // want `github.com/pkg/errors is imported`
+
+func foo(err error, a, b string) error {
+	if errors.Cause(err) == nil { // want `errors.Cause has to be migrated to errkit manually`
+		return errors.New("Not found") // want `errors.New can be migrated to errkit`
+	}
+	return errors.Wrapf(err, "%s %s", a, b) // want `errors.Wrapf has to be migrated to errkit manually`
+}
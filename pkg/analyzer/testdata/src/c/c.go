@@ -0,0 +1,14 @@
+package c
+
+import (
+	"github.com/pkg/errors" // want `github.com/pkg/errors is imported`
+)
+
+var ErrNotFound = errors.New("Not found") // want `errors.New can be migrated to errkit`
+
+func foo(err error) error {
+	if err == nil {
+		return errors.New("Not found") // want `errors.New can be migrated to errkit`
+	}
+	return errors.Wrap(err, "Failed to get secrets") // want `errors.Wrap can be migrated to errkit`
+}
@@ -0,0 +1,14 @@
+package d
+
+import (
+	"os"
+
+	"github.com/pkg/errors" // want `github.com/pkg/errors is imported`
+)
+
+func foo(a, b string) error {
+	if _, err := os.Stat(a); err != nil {
+		return err
+	}
+	return errors.Errorf("%s %s", a, b) // want `errors.Errorf can be migrated to errkit`
+}
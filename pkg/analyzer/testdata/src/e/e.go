@@ -0,0 +1,9 @@
+package e
+
+import (
+	"example.com/errors" // want `example.com/errors is imported`
+)
+
+func foo(err error) error {
+	return errors.Wrap(err, "Failed to get secrets") // want `errors.Wrap can be migrated to xerrkit`
+}
@@ -0,0 +1,14 @@
+package f
+
+import "github.com/pkg/errors" // want `github.com/pkg/errors is imported`
+
+func foo(err error, name string) error {
+	if err == nil {
+		return /* want `errors.Wrapf has to be migrated to errkit manually` */ errors.Wrapf(err,
+			"Failed to get %s", // the name
+			name)
+	}
+	return /* want `errors.Wrapf can be migrated to errkit` */ errors.Wrapf(err,
+		"Failed to get secret %s",
+		name)
+}
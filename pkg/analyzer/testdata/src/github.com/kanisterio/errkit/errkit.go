@@ -0,0 +1,5 @@
+package errkit
+
+func New(message string, details ...any) error             { return nil }
+func Wrap(err error, message string, details ...any) error { return nil }
+func NewSentinelErr(message string) error                  { return nil }
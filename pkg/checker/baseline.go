@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Baseline is the set of accepted findings.
+// Findings are matched by the path relative to the checked root, the kind and the text, so the baseline
+// survives unrelated changes shifting the lines and doesn't depend on the way the root is given.
+// Every baseline entry accepts a single finding.
+type Baseline struct {
+	counts map[baselineKey]int
+}
+
+type baselineKey struct {
+	path string
+	kind Kind
+	text string
+}
+
+func keyOf(f Finding) baselineKey {
+	return baselineKey{path: f.baselinePath(), kind: f.Kind, text: f.Text}
+}
+
+func (f Finding) baselinePath() string {
+	if f.Rel != "" {
+		return f.Rel
+	}
+
+	return f.Path
+}
+
+// ReadBaseline reads the baseline written by WriteBaseline.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var findings []Finding
+	if err := json.NewDecoder(r).Decode(&findings); err != nil {
+		return Baseline{}, err
+	}
+
+	baseline := Baseline{counts: map[baselineKey]int{}}
+	for _, f := range findings {
+		baseline.counts[keyOf(f)]++
+	}
+
+	return baseline, nil
+}
+
+// WriteBaseline writes the findings as the baseline, with the paths relative to the checked root.
+func WriteBaseline(w io.Writer, findings []Finding) error {
+	entries := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		f.Path, f.Rel = f.baselinePath(), ""
+		entries = append(entries, f)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(entries)
+}
+
+// Filter returns the findings which are not accepted by the baseline.
+func (b Baseline) Filter(findings []Finding) []Finding {
+	accepted := make(map[baselineKey]int, len(b.counts))
+	for key, count := range b.counts {
+		accepted[key] = count
+	}
+
+	var result []Finding
+	for _, f := range findings {
+		key := keyOf(f)
+		if accepted[key] > 0 {
+			accepted[key]--
+			continue
+		}
+		result = append(result, f)
+	}
+
+	return result
+}
@@ -0,0 +1,186 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/resolver"
+	"mig/pkg/traverser"
+)
+
+// Kind is the kind of the remaining usage of the source package, `github.com/pkg/errors` by default.
+type Kind string
+
+const (
+	// Import is an import of the source package
+	Import Kind = "import"
+	// Call is an invocation of the source package function
+	Call Kind = "call"
+	// Todo is a comment marking the code to be migrated manually
+	Todo Kind = "todo"
+	// Invalid is a file which can't be parsed, so it's not checked
+	Invalid Kind = "invalid"
+)
+
+// Finding is a single place in the code which is not migrated yet.
+type Finding struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Kind   Kind   `json:"kind"`
+	// Text is the source of the import spec, the invocation or the comment, or the parsing error of the invalid file
+	Text string `json:"text"`
+	// Rel is the slash-separated path relative to the checked root, the baseline is matched by.
+	// If empty, Path is used.
+	Rel string `json:"-"`
+	// Target is the import path of the package the code is migrated to, common.ErrkitImportPath if empty.
+	// It's not a part of the baseline.
+	Target string `json:"-"`
+}
+
+// String returns the finding in the `path:line:column: message` form.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", f.Path, f.Line, f.Column, f.message())
+}
+
+func (f Finding) message() string {
+	switch f.Kind {
+	case Import:
+		// The import spec ends with the quoted import path
+		importPath, err := strconv.Unquote(f.Text[strings.LastIndexByte(f.Text, ' ')+1:])
+		if err != nil {
+			importPath = f.Text
+		}
+		return fmt.Sprintf("%s is imported", importPath)
+	case Call:
+		target := f.Target
+		if target == "" {
+			target = common.ErrkitImportPath
+		}
+		return fmt.Sprintf("%s has to be migrated to %s", f.Text, target)
+	case Invalid:
+		return fmt.Sprintf("the file can't be checked: %s", f.Text)
+	default:
+		return fmt.Sprintf("%s is left in the code", f.Text)
+	}
+}
+
+// Check walks the tree under the root the same way the migration does and returns all remaining imports
+// and invocations of the source package and the comments containing the TODO marker.
+// Only the files selected by the filter are checked. If the marker is empty, the comments are not checked.
+// Files which can't be parsed are reported as Invalid findings, so the rest of the tree is still checked.
+func Check(root string, filter traverser.Filter, pkgs common.Packages, marker string) ([]Finding, error) {
+	var findings []Finding
+	_, err := traverser.WalkGoFiles(root, filter, func(path string) error {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		found, err := CheckFile(filepath.ToSlash(path), src, pkgs, marker)
+		if err != nil {
+			found = []Finding{invalid(filepath.ToSlash(path), err)}
+		}
+		for i := range found {
+			found[i].Rel = relPath(root, path)
+		}
+		findings = append(findings, found...)
+
+		return nil
+	})
+
+	return findings, err
+}
+
+// invalid returns the finding of the file which can't be parsed, located at the first syntax error.
+func invalid(path string, err error) Finding {
+	finding := Finding{Path: path, Line: 1, Column: 1, Kind: Invalid, Text: err.Error()}
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		finding.Line, finding.Column, finding.Text = list[0].Pos.Line, list[0].Pos.Column, list[0].Msg
+	}
+
+	return finding
+}
+
+// relPath returns the slash-separated path of the file relative to the root, or its name if the root is
+// the file itself, so the baseline doesn't depend on the way the root is given.
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	switch {
+	case err != nil:
+		return filepath.ToSlash(path)
+	case rel == ".":
+		return filepath.Base(path)
+	default:
+		return filepath.ToSlash(rel)
+	}
+}
+
+// CheckFile returns the remaining usages of the source package, `github.com/pkg/errors` by default,
+// in the source of a Go file, sorted by position. The empty packages are set to the defaults.
+func CheckFile(path string, src []byte, pkgs common.Packages, marker string) ([]Finding, error) {
+	pkgs = pkgs.WithDefaults()
+	source := pkgs.Source
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	finding := func(node ast.Node, kind Kind) Finding {
+		pos := fset.Position(node.Pos())
+		return Finding{
+			Path:   path,
+			Line:   pos.Line,
+			Column: pos.Column,
+			Kind:   kind,
+			Text:   string(src[pos.Offset:fset.Position(node.End()).Offset]),
+			Target: pkgs.Target,
+		}
+	}
+
+	var findings []Finding
+	for _, spec := range file.Imports {
+		// Blank and dot imports are reported too, even though they can't be referred by a selector
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == source {
+			findings = append(findings, finding(spec, Import))
+		}
+	}
+
+	if _, name := resolver.FindImport(file, source); name != "" {
+		for _, call := range resolver.FindCalls(file, name) {
+			findings = append(findings, finding(call, Call))
+		}
+	}
+
+	if marker = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(marker), "//")); marker != "" {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				if strings.Contains(comment.Text, marker) {
+					findings = append(findings, finding(comment, Todo))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+
+	return findings, nil
+}
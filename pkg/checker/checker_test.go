@@ -0,0 +1,165 @@
+package checker_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/checker"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/traverser"
+)
+
+const src = `package foo
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return pkgerrors.Wrapf(err, "Failed to get PVC %s", pvcName)
+	}
+	return errkit.Wrap(err, "%s %s", a, b) // TODO: migrate manually
+}
+`
+
+func TestCheckFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		marker   string
+		expected []checker.Finding
+	}{
+		{
+			name:   "Imports, invocations and TODO markers are found",
+			src:    src,
+			marker: "// TODO: migrate manually",
+			expected: []checker.Finding{
+				{Path: "foo.go", Line: 6, Column: 2, Kind: checker.Import, Text: `pkgerrors "github.com/pkg/errors"`, Target: common.ErrkitImportPath},
+				{Path: "foo.go", Line: 11, Column: 10, Kind: checker.Call, Text: `pkgerrors.Wrapf(err, "Failed to get PVC %s", pvcName)`, Target: common.ErrkitImportPath},
+				{Path: "foo.go", Line: 13, Column: 41, Kind: checker.Todo, Text: "// TODO: migrate manually", Target: common.ErrkitImportPath},
+			},
+		},
+		{
+			name:   "TODO markers are not checked without the marker",
+			src:    src,
+			marker: "",
+			expected: []checker.Finding{
+				{Path: "foo.go", Line: 6, Column: 2, Kind: checker.Import, Text: `pkgerrors "github.com/pkg/errors"`, Target: common.ErrkitImportPath},
+				{Path: "foo.go", Line: 11, Column: 10, Kind: checker.Call, Text: `pkgerrors.Wrapf(err, "Failed to get PVC %s", pvcName)`, Target: common.ErrkitImportPath},
+			},
+		},
+		{
+			name: "Blank import is found",
+			src: `package foo
+
+import _ "github.com/pkg/errors"
+`,
+			expected: []checker.Finding{
+				{Path: "foo.go", Line: 3, Column: 8, Kind: checker.Import, Text: `_ "github.com/pkg/errors"`, Target: common.ErrkitImportPath},
+			},
+		},
+		{
+			name: "Migrated file is clean",
+			src: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.New("Not found")
+}
+`,
+			marker: "// TODO: migrate manually",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := checker.CheckFile("foo.go", []byte(tt.src), common.Packages{}, tt.marker)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, findings)
+		})
+	}
+}
+
+func TestCheckFilePackages(t *testing.T) {
+	src := `package foo
+
+import "example.com/errors"
+
+func foo() error {
+	return errors.New("Not found")
+}
+`
+	pkgs := common.Packages{Source: "example.com/errors", Target: "example.com/errkit"}
+	findings, err := checker.CheckFile("foo.go", []byte(src), pkgs, "")
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 2) {
+		assert.Equal(t, `foo.go:3:8: example.com/errors is imported`, findings[0].String())
+		assert.Equal(t, `foo.go:6:9: errors.New("Not found") has to be migrated to example.com/errkit`, findings[1].String())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "pkg"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "foo.go"), []byte(src), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "bad.go"), []byte("package foo\n\nfunc {\n"), 0644))
+
+	// The file which can't be parsed doesn't stop the check
+	findings, err := checker.Check(root, traverser.Filter{}, common.Packages{}, "")
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 3) {
+		assert.Equal(t, checker.Invalid, findings[0].Kind)
+		assert.Equal(t, filepath.ToSlash(filepath.Join(root, "pkg", "bad.go"))+":3:6: the file can't be checked: expected 'IDENT', found '{'", findings[0].String())
+		assert.Equal(t, "pkg/bad.go", findings[0].Rel)
+		assert.Equal(t, "pkg/foo.go", findings[1].Rel)
+	}
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, checker.WriteBaseline(&buf, findings))
+	assert.Contains(t, buf.String(), `"path": "pkg/foo.go"`)
+	baseline, err := checker.ReadBaseline(&buf)
+	assert.NoError(t, err)
+
+	// The baseline matches the findings regardless of the way the root is given
+	findings, err = checker.Check(filepath.Join(root, "pkg", ".."), traverser.Filter{}, common.Packages{}, "")
+	assert.NoError(t, err)
+	assert.Len(t, findings, 3)
+	assert.Empty(t, baseline.Filter(findings))
+}
+
+func TestFindingString(t *testing.T) {
+	finding := checker.Finding{Path: "foo.go", Line: 11, Column: 10, Kind: checker.Call, Text: `errors.New("x")`}
+	assert.Equal(t, `foo.go:11:10: errors.New("x") has to be migrated to github.com/kanisterio/errkit`, finding.String())
+
+	finding.Target = "example.com/errkit"
+	assert.Equal(t, `foo.go:11:10: errors.New("x") has to be migrated to example.com/errkit`, finding.String())
+
+	finding = checker.Finding{Path: "foo.go", Line: 6, Column: 2, Kind: checker.Import, Text: `pkgerrors "github.com/pkg/errors"`}
+	assert.Equal(t, `foo.go:6:2: github.com/pkg/errors is imported`, finding.String())
+}
+
+func TestBaseline(t *testing.T) {
+	accepted := checker.Finding{Path: "foo.go", Line: 11, Column: 10, Kind: checker.Call, Text: `errors.New("x")`}
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, checker.WriteBaseline(&buf, []checker.Finding{accepted}))
+
+	baseline, err := checker.ReadBaseline(&buf)
+	assert.NoError(t, err)
+
+	moved := accepted
+	moved.Line = 20
+	other := checker.Finding{Path: "bar.go", Line: 11, Column: 10, Kind: checker.Call, Text: `errors.New("x")`}
+
+	// The baseline entry accepts a single finding only, even if the line has changed
+	assert.Equal(t, []checker.Finding{moved, other}, baseline.Filter([]checker.Finding{moved, moved, other}))
+}
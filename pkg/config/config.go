@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"mig/pkg/migrator"
+)
+
+// FileName is the name of the project configuration file
+const FileName = ".migr.yaml"
+
+// Config is the project configuration, pinning the migration settings of the repository, e.g.
+//
+//	engine: v3
+//	include: ["pkg/**"]
+//	exclude: ["zz_generated*.go"]
+//	fieldNames:
+//	  ns: namespace
+//	todo: "// TODO(errkit): migrate manually"
+//	rules: migr-rules.yaml
+//	output:
+//	  report: migr-report.json
+//
+// Relative paths are resolved against the directory of the configuration file.
+type Config struct {
+	// Engine is the migrator version
+	Engine migrator.MigratorVersion `yaml:"engine"`
+	// Include and Exclude are the globs selecting the files to be migrated, relative to the configuration directory
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// All disables skipping hidden, vendor, testdata, git-ignored and generated files
+	All bool `yaml:"all"`
+	// FieldNames map the field names inferred from the messages to the ones to use instead
+	FieldNames map[string]string `yaml:"fieldNames"`
+	// Todo is the comment marking the code to be migrated manually, empty to disable the marking.
+	// Nil if not configured.
+	Todo *string `yaml:"todo"`
+	// Rules is the path of the rewrite rules file
+	Rules   string  `yaml:"rules"`
+	Imports Imports `yaml:"imports"`
+	Output  Output  `yaml:"output"`
+	// Backup keeps the original content of the changed files next to them with the `.orig` suffix
+	Backup bool `yaml:"backup"`
+	// BackupDir is the directory the original content of the changed files is saved to
+	BackupDir string `yaml:"backupDir"`
+	// Journal is the directory the run journals are kept in, the user cache directory by default
+	Journal string `yaml:"journal"`
+
+	// Dir is the directory of the configuration file
+	Dir string `yaml:"-"`
+}
+
+// Imports are the packages migrated from and to, the defaults are used for the empty fields.
+type Imports struct {
+	// Source and Target are the import paths of the packages
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	// Qualifier is the identifier the migrated code refers to the target package by
+	Qualifier string `yaml:"qualifier"`
+}
+
+// Output configures the files the results are written to.
+type Output struct {
+	// Report is the path of the JSON report
+	Report string `yaml:"report"`
+	// SARIF is the path of the SARIF log
+	SARIF string `yaml:"sarif"`
+}
+
+// Find looks for the configuration file in the directory of the target path and its parents.
+// It returns an empty string if there is none.
+func Find(target string) (string, error) {
+	dir, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Discover loads the configuration file applying to the target path.
+// It returns nil if there is none.
+func Discover(target string) (*Config, error) {
+	path, err := Find(target)
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	return Load(path)
+}
+
+// Load reads the configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dir = filepath.Dir(abs)
+
+	return cfg, nil
+}
+
+// Parse parses the configuration. Unknown fields are rejected, so typos don't go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch cfg.Engine {
+	case "", migrator.V1, migrator.V2, migrator.V3:
+	default:
+		return nil, fmt.Errorf("unknown engine %q", cfg.Engine)
+	}
+
+	return cfg, nil
+}
+
+// Path resolves the path relative to the configuration directory.
+func (c *Config) Path(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.Dir, path)
+}
@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/config"
+	"mig/pkg/migrator"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := config.Parse([]byte(`
+engine: v3
+include: ["pkg/**"]
+exclude: ["zz_generated*.go"]
+all: true
+fieldNames:
+  ns: namespace
+todo: ""
+rules: rules.yaml
+imports:
+  source: github.com/pkg/errors
+  target: github.com/kanisterio/errkit
+output:
+  report: report.json
+  sarif: report.sarif
+backup: true
+backupDir: .migr-backup
+journal: .migr-journal
+`))
+	assert.NoError(t, err)
+
+	todo := ""
+	assert.Equal(t, &config.Config{
+		Engine:     migrator.V3,
+		Include:    []string{"pkg/**"},
+		Exclude:    []string{"zz_generated*.go"},
+		All:        true,
+		FieldNames: map[string]string{"ns": "namespace"},
+		Todo:       &todo,
+		Rules:      "rules.yaml",
+		Imports:    config.Imports{Source: "github.com/pkg/errors", Target: "github.com/kanisterio/errkit"},
+		Output:     config.Output{Report: "report.json", SARIF: "report.sarif"},
+		Backup:     true,
+		BackupDir:  ".migr-backup",
+		Journal:    ".migr-journal",
+	}, cfg)
+}
+
+func TestParseEmpty(t *testing.T) {
+	cfg, err := config.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.Config{}, cfg)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Unknown engine", data: "engine: v4"},
+		{name: "Unknown field", data: "engines: v3"},
+		{name: "Malformed document", data: "include: {"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Parse([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "pkg", "foo")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(nested, "foo.go"), []byte("package foo\n"), 0644))
+
+	cfg, err := config.Discover(nested)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, config.FileName), []byte("engine: v3\nrules: rules.yaml\n"), 0644))
+
+	for _, target := range []string{root, nested, filepath.Join(nested, "foo.go")} {
+		cfg, err = config.Discover(target)
+		assert.NoError(t, err)
+		if assert.NotNil(t, cfg) {
+			assert.Equal(t, migrator.V3, cfg.Engine)
+			assert.Equal(t, filepath.Join(root, "rules.yaml"), cfg.Path(cfg.Rules))
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around the changes
+const contextLines = 3
+
+// op is a kind of a line edit
+type op byte
+
+const (
+	opEqual  op = ' '
+	opDelete op = '-'
+	opInsert op = '+'
+)
+
+// lineEdit is a single line of the edit script
+type lineEdit struct {
+	op      op
+	oldLine int // 0-based index in the old lines, valid for opEqual and opDelete
+	newLine int // 0-based index in the new lines, valid for opEqual and opInsert
+}
+
+// Unified returns the unified diff of oldContent and newContent with the file name in the headers,
+// in the form accepted by `git apply`. It returns an empty string if the contents are equal.
+func Unified(name string, oldContent, newContent []byte) string {
+	oldLines := splitLines(string(oldContent))
+	newLines := splitLines(string(newContent))
+
+	edits := computeEdits(oldLines, newLines)
+
+	sb := strings.Builder{}
+	for _, h := range hunks(edits) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+		}
+		writeHunk(&sb, h, oldLines, newLines)
+	}
+
+	return sb.String()
+}
+
+// splitLines splits the content into lines keeping the line endings,
+// so the missing newline at the end of the file can be reported.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// computeEdits returns the shortest edit script transforming a into b using Myers' O(ND) difference algorithm
+// in linear space: the middle snake found by bisect splits the sequences in two, which are compared recursively.
+// Common prefix and suffix are trimmed at every level first.
+func computeEdits(a, b []string) []lineEdit {
+	edits := make([]lineEdit, 0, max(len(a), len(b)))
+	compare(a, b, 0, 0, &edits)
+
+	return edits
+}
+
+// compare appends the edits transforming a into b, the sequences starting at the aStart and bStart lines.
+func compare(a, b []string, aStart, bStart int, edits *[]lineEdit) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		*edits = append(*edits, lineEdit{op: opEqual, oldLine: aStart + prefix, newLine: bStart + prefix})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	aStart, bStart = aStart+prefix, bStart+prefix
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	if x, y, ok := bisect(a, b); ok {
+		compare(a[:x], b[:y], aStart, bStart, edits)
+		compare(a[x:], b[y:], aStart+x, bStart+y, edits)
+	} else {
+		for i := range a {
+			*edits = append(*edits, lineEdit{op: opDelete, oldLine: aStart + i, newLine: bStart})
+		}
+		for i := range b {
+			*edits = append(*edits, lineEdit{op: opInsert, oldLine: aStart + len(a), newLine: bStart + i})
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		*edits = append(*edits, lineEdit{op: opEqual, oldLine: aStart + len(a) + i, newLine: bStart + len(b) + i})
+	}
+}
+
+// bisect finds the point the shortest edit script of a and b passes through in the middle, searching from both ends
+// at once and keeping only the furthest reaching paths of the current step. The sequences are expected to differ
+// at both ends. It reports false if either sequence is empty or the paths don't meet, in which case a is replaced
+// with b as a whole.
+func bisect(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// forward and backward hold the furthest x reached on every diagonal k, counted from the start and the end
+	forward, backward := make([]int, 2*offset+1), make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// The paths meet on a forward step if the difference of the lengths is odd
+	front := delta%2 != 0
+	// Diagonals leaving the edit graph are not extended anymore
+	kStart, kEnd, kBackStart, kBackEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + kStart; k <= d-kEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1] // move down
+			} else {
+				x = forward[offset+k-1] + 1 // move right
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			switch back := offset + delta - k; {
+			case x > n:
+				kEnd += 2
+			case y > m:
+				kStart += 2
+			case front && back >= 0 && back < len(backward) && backward[back] != -1:
+				if x >= n-backward[back] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + kBackStart; k <= d-kBackEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			switch fwd := offset + delta - k; {
+			case x > n:
+				kBackEnd += 2
+			case y > m:
+				kBackStart += 2
+			case !front && fwd >= 0 && fwd < len(forward) && forward[fwd] != -1:
+				if fx := forward[fwd]; fx >= n-x {
+					return fx, fx - (fwd - offset), true
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// hunks groups the edits into hunks surrounded by up to contextLines unchanged lines.
+func hunks(edits []lineEdit) [][]lineEdit {
+	var result [][]lineEdit
+	start, last := -1, -1
+	for i, e := range edits {
+		if e.op == opEqual {
+			continue
+		}
+
+		// Unchanged lines between the changes don't fit into the context of both, start a new hunk
+		if start != -1 && i-last-1 > 2*contextLines {
+			result = append(result, edits[start:min(len(edits), last+contextLines+1)])
+			start = -1
+		}
+
+		if start == -1 {
+			start = max(0, i-contextLines)
+		}
+		last = i
+	}
+
+	if start != -1 {
+		result = append(result, edits[start:min(len(edits), last+contextLines+1)])
+	}
+
+	return result
+}
+
+// writeHunk writes the hunk header followed by the hunk lines.
+func writeHunk(sb *strings.Builder, hunk []lineEdit, oldLines, newLines []string) {
+	oldStart, oldCount, newStart, newCount := -1, 0, -1, 0
+	for _, e := range hunk {
+		if e.op != opInsert {
+			if oldStart == -1 {
+				oldStart = e.oldLine
+			}
+			oldCount++
+		}
+		if e.op != opDelete {
+			if newStart == -1 {
+				newStart = e.newLine
+			}
+			newCount++
+		}
+	}
+
+	// The start of an empty range is the line preceding it
+	if oldStart == -1 {
+		oldStart = hunk[0].oldLine - 1
+	}
+	if newStart == -1 {
+		newStart = hunk[0].newLine - 1
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, e := range hunk {
+		line := ""
+		switch e.op {
+		case opEqual, opDelete:
+			line = oldLines[e.oldLine]
+		case opInsert:
+			line = newLines[e.newLine]
+		}
+
+		sb.WriteByte(byte(e.op))
+		sb.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the 0-based start and the count of lines as the hunk header range.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,204 @@
+package diff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/diff"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "Equal contents",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "Single changed line with context",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:  "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			expected: `--- a/foo.go
++++ b/foo.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "Distant changes are split into hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: `--- a/foo.go
++++ b/foo.go
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+		{
+			name: "Inserted and deleted lines",
+			old:  "a\nb\nc\n",
+			new:  "a\nc\nd\n",
+			expected: `--- a/foo.go
++++ b/foo.go
+@@ -1,3 +1,3 @@
+ a
+-b
+ c
++d
+`,
+		},
+		{
+			name: "Insertion into empty file",
+			old:  "",
+			new:  "a\n",
+			expected: `--- a/foo.go
++++ b/foo.go
+@@ -0,0 +1,1 @@
++a
+`,
+		},
+		{
+			name: "Missing newline at end of file",
+			old:  "a\nb",
+			new:  "a\nc",
+			expected: `--- a/foo.go
++++ b/foo.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := diff.Unified("foo.go", []byte(tt.old), []byte(tt.new))
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// apply applies the unified diff of the lines ending with newlines to the old content.
+func apply(t *testing.T, old, patch string) string {
+	oldLines := strings.SplitAfter(old, "\n")
+	sb := strings.Builder{}
+	next := 0
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"), line == "":
+		case strings.HasPrefix(line, "@@"):
+			var start, count int
+			_, err := fmt.Sscanf(line, "@@ -%d,%d", &start, &count)
+			assert.NoError(t, err)
+			if count == 0 {
+				start++
+			}
+			for ; next < start-1; next++ {
+				sb.WriteString(oldLines[next])
+			}
+		case line[0] == ' ':
+			assert.Equal(t, oldLines[next], line[1:])
+			sb.WriteString(line[1:])
+			next++
+		case line[0] == '-':
+			assert.Equal(t, oldLines[next], line[1:])
+			next++
+		case line[0] == '+':
+			sb.WriteString(line[1:])
+		}
+	}
+	for ; next < len(oldLines); next++ {
+		sb.WriteString(oldLines[next])
+	}
+
+	return sb.String()
+}
+
+// lcs returns the length of the longest common subsequence of the lines.
+func lcs(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func TestUnifiedShortest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	lines := func(n, alphabet int) []string {
+		result := make([]string, n)
+		for i := range result {
+			result[i] = strconv.Itoa(rnd.Intn(alphabet)) + "\n"
+		}
+
+		return result
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := lines(rnd.Intn(30), 1+rnd.Intn(6)), lines(rnd.Intn(30), 1+rnd.Intn(6))
+		old, new := strings.Join(a, ""), strings.Join(b, "")
+
+		patch := diff.Unified("foo.go", []byte(old), []byte(new))
+		assert.Equal(t, new, apply(t, old, patch), "diff of %q and %q", old, new)
+
+		changed := 0
+		for _, line := range strings.Split(patch, "\n") {
+			if (strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")) ||
+				(strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) {
+				changed++
+			}
+		}
+		assert.Equal(t, len(a)+len(b)-2*lcs(a, b), changed, "diff of %q and %q", old, new)
+	}
+}
+
+func TestUnifiedLarge(t *testing.T) {
+	// Completely different contents take the most steps, which must not keep the state of every step
+	a, b := make([]string, 5000), make([]string, 5000)
+	for i := range a {
+		a[i], b[i] = fmt.Sprintf("old %d\n", i), fmt.Sprintf("new %d\n", i)
+	}
+	old, new := strings.Join(a, ""), strings.Join(b, "")
+
+	assert.Equal(t, new, apply(t, old, diff.Unified("foo.go", []byte(old), []byte(new))))
+}
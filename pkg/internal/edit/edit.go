@@ -0,0 +1,26 @@
+package edit
+
+import "sort"
+
+// Edit replaces src[Start:End] with Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Apply applies non-overlapping edits to the source. Edits inserting at the same offset are applied
+// in the given order.
+func Apply(src []byte, edits []Edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+
+	result := make([]byte, 0, len(src))
+	pos := 0
+	for _, e := range edits {
+		result = append(result, src[pos:e.Start]...)
+		result = append(result, e.Text...)
+		pos = e.End
+	}
+
+	return append(result, src[pos:]...)
+}
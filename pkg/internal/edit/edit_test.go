@@ -0,0 +1,49 @@
+package edit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/internal/edit"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		edits    []edit.Edit
+		expected string
+	}{
+		{
+			name:     "No edits",
+			src:      "errors.New(msg)",
+			expected: "errors.New(msg)",
+		},
+		{
+			name: "Edits are applied in the order of offsets",
+			src:  "errors.Wrap(errors.New(msg), msg)",
+			edits: []edit.Edit{
+				{Start: 12, End: 27, Text: "errkit.New(msg)"},
+				{Start: 0, End: 11, Text: "errkit.Wrap"},
+			},
+			expected: "errkit.Wrap(errkit.New(msg), msg)",
+		},
+		{
+			name: "Insertions at the same offset keep their order",
+			src:  "return err",
+			edits: []edit.Edit{
+				{Start: 10, End: 10, Text: " // first"},
+				{Start: 10, End: 10, Text: " // second"},
+			},
+			expected: "return err // first // second",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(edit.Apply([]byte(tt.src), tt.edits)))
+		})
+	}
+}
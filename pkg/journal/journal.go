@@ -0,0 +1,322 @@
+package journal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mig/pkg/util"
+)
+
+const (
+	// fileName is the name of the journal file within the run directory
+	fileName = "journal.jsonl"
+	// objectsDir is the directory of the run directory holding the original content of the files by its hash
+	objectsDir = "objects"
+)
+
+// Journal is the record of the files changed by a run, allowing to undo it.
+// It is kept in the directory named by the run ID, as JSON lines: the run itself followed by the files.
+type Journal struct {
+	// ID identifies the run
+	ID string `json:"id"`
+	// Time is the time the run started at
+	Time time.Time `json:"time"`
+	// Root is the path the run migrated
+	Root string `json:"root"`
+	// Files are the changed files in the order they were saved in
+	Files []File `json:"-"`
+}
+
+// File is the change of a file, the content is identified by its SHA-256 hash.
+type File struct {
+	// Path is the absolute path of the file
+	Path string `json:"path"`
+	// Before is the hash of the original content
+	Before string `json:"before"`
+	// After is the hash of the content written by the run
+	After string `json:"after"`
+}
+
+// DefaultDir returns the directory the journals are kept in by default, within the user cache directory.
+func DefaultDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cache, "migr", "journal"), nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of the content.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Recorder writes the journal of the run as the files are changed. It's safe for concurrent use.
+type Recorder struct {
+	dir     string // run directory
+	journal Journal
+
+	mu    sync.Mutex
+	file  *os.File
+	count int
+}
+
+// Begin starts the journal of the run migrating the root in the directory of the journals.
+func Begin(dir, root string) (*Recorder, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id, err := newID(now)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		dir:     filepath.Join(dir, id),
+		journal: Journal{ID: id, Time: now.UTC(), Root: abs},
+	}
+	if err := os.MkdirAll(filepath.Join(r.dir, objectsDir), 0755); err != nil {
+		return nil, err
+	}
+
+	r.file, err = os.OpenFile(filepath.Join(r.dir, fileName), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.append(r.journal); err != nil {
+		_ = r.file.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// newID returns the run ID, the start time followed by a random suffix, so the IDs sort by time.
+func newID(t time.Time) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+// ID returns the run ID.
+func (r *Recorder) ID() string {
+	return r.journal.ID
+}
+
+// Len returns the number of the recorded files.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.count
+}
+
+// Record saves the original content of the file and records the change. It has to be called before the file
+// is written, so the journal covers the change even if the run is interrupted.
+func (r *Recorder) Record(path string, before, after []byte) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	f := File{Path: abs, Before: Hash(before), After: Hash(after)}
+	if err := util.WriteFileAtomically(filepath.Join(r.dir, objectsDir, f.Before), before, 0644); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.append(f); err != nil {
+		return err
+	}
+	r.count++
+
+	return nil
+}
+
+// append writes the JSON line to the journal file and flushes it to the disk.
+func (r *Recorder) append(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return r.file.Sync()
+}
+
+// Close finishes the journal. The journal of the run which changed no files is removed.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if r.count == 0 {
+		return os.RemoveAll(r.dir)
+	}
+
+	return nil
+}
+
+// Load reads the journal of the run from the directory of the journals.
+func Load(dir, id string) (*Journal, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid run ID %q", id)
+	}
+
+	file, err := os.Open(filepath.Join(dir, id, fileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no journal of run %s in %s", id, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	j := &Journal{}
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(j); err != nil {
+		return nil, fmt.Errorf("journal of run %s is corrupted: %w", id, err)
+	}
+	for {
+		var f File
+		err := decoder.Decode(&f)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// The run was interrupted while writing the last line, which wasn't written to the file then
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, fmt.Errorf("journal of run %s is corrupted: %w", id, err)
+		}
+		j.Files = append(j.Files, f)
+	}
+
+	return j, nil
+}
+
+// List returns the journals found in the directory of the journals, the latest run first.
+func List(dir string) ([]*Journal, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var journals []*Journal
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		j, err := Load(dir, entry.Name())
+		if err != nil {
+			continue // Not a journal
+		}
+		journals = append(journals, j)
+	}
+	sort.SliceStable(journals, func(i, k int) bool {
+		return journals[i].Time.After(journals[k].Time)
+	})
+
+	return journals, nil
+}
+
+// Status is the outcome of restoring a file.
+type Status string
+
+const (
+	// Restored files got their original content back
+	Restored Status = "restored"
+	// Unchanged files have the original content already, e.g. the run was undone before
+	Unchanged Status = "unchanged"
+	// Modified files were edited after the run, so they are left as they are
+	Modified Status = "modified after the run"
+	// Missing files were removed after the run
+	Missing Status = "missing"
+	// Failed files failed to be restored, see the error
+	Failed Status = "failed"
+)
+
+// Outcome is the outcome of restoring a file.
+type Outcome struct {
+	Path   string
+	Status Status
+	Err    error
+}
+
+// Undo restores the original content of the files changed by the run. Only the files having the content
+// written by the run are restored, those edited again after the run are refused, i.e. left as they are.
+func Undo(dir, id string) ([]Outcome, error) {
+	j, err := Load(dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]Outcome, 0, len(j.Files))
+	for _, f := range j.Files {
+		outcomes = append(outcomes, restore(filepath.Join(dir, id), f))
+	}
+
+	return outcomes, nil
+}
+
+// restore restores the original content of the file unless it was edited after the run.
+func restore(runDir string, f File) Outcome {
+	current, err := os.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Outcome{Path: f.Path, Status: Missing}
+	case err != nil:
+		return Outcome{Path: f.Path, Status: Failed, Err: err}
+	}
+
+	switch Hash(current) {
+	case f.Before:
+		return Outcome{Path: f.Path, Status: Unchanged}
+	case f.After:
+	default:
+		return Outcome{Path: f.Path, Status: Modified}
+	}
+
+	original, err := os.ReadFile(filepath.Join(runDir, objectsDir, f.Before))
+	if err == nil && Hash(original) != f.Before {
+		err = fmt.Errorf("original content is corrupted")
+	}
+	if err == nil {
+		err = util.ReplaceFile(f.Path, original)
+	}
+	if err != nil {
+		return Outcome{Path: f.Path, Status: Failed, Err: err}
+	}
+
+	return Outcome{Path: f.Path, Status: Restored}
+}
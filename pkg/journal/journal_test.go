@@ -0,0 +1,136 @@
+package journal_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/journal"
+)
+
+// run records the changes of the files by the name under the root, writing the migrated content
+// the same way the traversal does, and returns the run ID.
+func run(t *testing.T, dir, root string, changes map[string][2]string) string {
+	r, err := journal.Begin(dir, root)
+	assert.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	for name, change := range changes {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.WriteFile(path, []byte(change[0]), 0644))
+
+		wg.Add(1)
+		go func(path string, before, after []byte) {
+			defer wg.Done()
+			assert.NoError(t, r.Record(path, before, after))
+			assert.NoError(t, os.WriteFile(path, after, 0644))
+		}(path, []byte(change[0]), []byte(change[1]))
+	}
+	wg.Wait()
+
+	assert.Equal(t, len(changes), r.Len())
+	assert.NoError(t, r.Close())
+
+	return r.ID()
+}
+
+func TestUndo(t *testing.T) {
+	dir := t.TempDir()
+	root := t.TempDir()
+	id := run(t, dir, root, map[string][2]string{
+		"restored.go": {"errors", "errkit"},
+		"modified.go": {"errors", "errkit"},
+		"missing.go":  {"errors", "errkit"},
+		"undone.go":   {"errors", "errkit"},
+	})
+
+	j, err := journal.Load(dir, id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, j.ID)
+	assert.Equal(t, root, j.Root)
+	assert.Len(t, j.Files, 4)
+
+	path := func(name string) string {
+		return filepath.Join(root, name)
+	}
+	assert.NoError(t, os.WriteFile(path("modified.go"), []byte("errkit edited"), 0644))
+	assert.NoError(t, os.Remove(path("missing.go")))
+	assert.NoError(t, os.WriteFile(path("undone.go"), []byte("errors"), 0644))
+
+	outcomes, err := journal.Undo(dir, id)
+	assert.NoError(t, err)
+
+	statuses := map[string]journal.Status{}
+	for _, o := range outcomes {
+		assert.NoError(t, o.Err)
+		statuses[filepath.Base(o.Path)] = o.Status
+	}
+	assert.Equal(t, map[string]journal.Status{
+		"restored.go": journal.Restored,
+		"modified.go": journal.Modified,
+		"missing.go":  journal.Missing,
+		"undone.go":   journal.Unchanged,
+	}, statuses)
+
+	for name, expected := range map[string]string{
+		"restored.go": "errors",
+		"modified.go": "errkit edited",
+		"undone.go":   "errors",
+	} {
+		content, err := os.ReadFile(path(name))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(content), name)
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	root := t.TempDir()
+
+	journals, err := journal.List(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, journals)
+
+	first := run(t, dir, root, map[string][2]string{"foo.go": {"errors", "errkit"}})
+	second := run(t, dir, root, map[string][2]string{"foo.go": {"errkit", "errkit 2"}, "bar.go": {"errors", "errkit"}})
+	// The journal of the run changing no files is removed
+	run(t, dir, root, nil)
+
+	journals, err = journal.List(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, journals, 2) {
+		assert.Equal(t, second, journals[0].ID)
+		assert.Len(t, journals[0].Files, 2)
+		assert.Equal(t, first, journals[1].ID)
+		assert.Len(t, journals[1].Files, 1)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, id := range []string{"", "..", "../foo", "missing"} {
+		_, err := journal.Load(dir, id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestLoadInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	root := t.TempDir()
+	id := run(t, dir, root, map[string][2]string{"foo.go": {"errors", "errkit"}})
+
+	// The line being written when the run was killed is ignored
+	file, err := os.OpenFile(filepath.Join(dir, id, "journal.jsonl"), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"path":"/foo/bar.go","bef`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	j, err := journal.Load(dir, id)
+	assert.NoError(t, err)
+	assert.Len(t, j.Files, 1)
+}
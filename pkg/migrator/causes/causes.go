@@ -0,0 +1,473 @@
+package causes
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"mig/pkg/internal/edit"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/imports"
+	"mig/pkg/migrator/resolver"
+)
+
+const (
+	stdErrorsImportPath = "errors"
+	matcherName         = "RewriteCause"
+)
+
+// rewriter holds the state of a single file rewriting
+type rewriter struct {
+	fset   *token.FileSet
+	src    []byte
+	name   string // name the source package is referred by
+	std    string // name the standard library `errors` package is referred by
+	edits  []edit.Edit
+	calls  []common.Call
+	offset func(token.Pos) int
+	// inits are the init and post statements of other statements, which can't be replaced with several statements
+	inits map[ast.Stmt]bool
+}
+
+// Rewrite replaces statements inspecting the result of `errors.Cause` with the standard library `errors` functions:
+//   - `errors.Cause(err) == ErrX` and `!=` comparisons turn into `errors.Is(err, ErrX)`;
+//   - type switches on `errors.Cause(err)` turn into chains of `if` statements using `errors.As`;
+//   - `v, ok := errors.Cause(err).(T)` assertions turn into `errors.As` calls.
+//
+// The source path is the import path of the package `errors.Cause` belongs to, e.g. `github.com/pkg/errors`.
+// The standard library package is imported if needed, under the `stderrors` alias if the `errors` name is taken
+// by the source package. Unsupported statements are kept as is, leaving the Cause invocation to the handlers.
+// It returns the rewritten source and the results of the rewritten statements,
+// or the source as is and no results if nothing was rewritten.
+func Rewrite(src []byte, source string) ([]byte, []common.Call, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec, name := resolver.FindImport(file, source)
+	if spec == nil {
+		return src, nil, nil
+	}
+
+	tokFile := fset.File(file.Pos())
+	std, importStd := stdName(file, name)
+	r := &rewriter{
+		fset:   fset,
+		src:    src,
+		name:   name,
+		std:    std,
+		offset: tokFile.Offset,
+		inits:  map[ast.Stmt]bool{},
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			// Nested comparisons are not rewritten, they are kept as the operands of the rewritten one
+			return !r.comparison(n)
+		case *ast.TypeSwitchStmt:
+			r.typeSwitch(n)
+		case *ast.IfStmt:
+			r.inits[n.Init] = true
+			r.ifAssertion(n)
+		case *ast.SwitchStmt:
+			r.inits[n.Init] = true
+		case *ast.ForStmt:
+			r.inits[n.Init], r.inits[n.Post] = true, true
+		case *ast.AssignStmt:
+			r.assertion(n)
+		}
+		return true
+	})
+
+	if len(r.edits) == 0 {
+		return src, nil, nil
+	}
+
+	result := edit.Apply(src, r.edits)
+	if importStd {
+		if result, err = addImport(result, std); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sort.SliceStable(r.calls, func(i, j int) bool {
+		if r.calls[i].Line != r.calls[j].Line {
+			return r.calls[i].Line < r.calls[j].Line
+		}
+		return r.calls[i].Column < r.calls[j].Column
+	})
+
+	return result, r.calls, nil
+}
+
+// stdName returns the name the standard library `errors` package is referred by,
+// and whether it has to be imported.
+func stdName(file *ast.File, errorsName string) (string, bool) {
+	taken := false
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case path == stdErrorsImportPath && spec.Name == nil:
+			return "errors", false
+		case path == stdErrorsImportPath && spec.Name.Name != "_" && spec.Name.Name != ".":
+			return spec.Name.Name, false
+		case spec.Name == nil && resolver.PackageName(path) == "errors", spec.Name != nil && spec.Name.Name == "errors":
+			taken = true
+		}
+	}
+
+	if taken || errorsName == "errors" {
+		return imports.StdErrorsAlias, true
+	}
+
+	return "errors", true
+}
+
+// comparison rewrites `errors.Cause(err) == target` into `errors.Is(err, target)`.
+func (r *rewriter) comparison(expr *ast.BinaryExpr) bool {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return false
+	}
+
+	err, target := r.causeArg(expr.X), expr.Y
+	if err == nil {
+		err, target = r.causeArg(expr.Y), expr.X
+	}
+	if err == nil || r.causeArg(target) != nil {
+		return false
+	}
+
+	text := fmt.Sprintf("%s.Is(%s, %s)", r.std, r.text(err), r.text(target))
+	if expr.Op == token.NEQ {
+		text = "!" + text
+	}
+	r.replace(expr, expr.Pos(), expr.End(), text)
+
+	return true
+}
+
+// typeSwitch rewrites the type switch on `errors.Cause(err)` into the chain of `if` statements using `errors.As`.
+// Only single type cases are supported. The default case has to be the last one, as it turns into `else` branch.
+// The argument of `errors.Cause` is evaluated by every `if` condition, so only variables and their fields
+// are supported, e.g. `err` or `resp.Err`, not function calls.
+func (r *rewriter) typeSwitch(stmt *ast.TypeSwitchStmt) {
+	if stmt.Init != nil || len(stmt.Body.List) == 0 {
+		return
+	}
+
+	var binding string
+	var assert *ast.TypeAssertExpr
+	switch s := stmt.Assign.(type) {
+	case *ast.AssignStmt:
+		binding = s.Lhs[0].(*ast.Ident).Name
+		assert, _ = s.Rhs[0].(*ast.TypeAssertExpr)
+	case *ast.ExprStmt:
+		assert, _ = s.X.(*ast.TypeAssertExpr)
+	}
+	if assert == nil {
+		return
+	}
+
+	err := r.causeArg(assert.X)
+	if err == nil || !isVariable(err) {
+		return
+	}
+
+	var edits []edit.Edit
+	asserted := false
+	for i, s := range stmt.Body.List {
+		clause := s.(*ast.CaseClause)
+		last := i == len(stmt.Body.List)-1
+		if len(clause.List) > 1 || (clause.List == nil && !last) || hasBreak(clause.Body) {
+			return
+		}
+
+		var cond string
+		switch {
+		case clause.List == nil:
+			// The binding has the type of the switch expression there, which is not available anymore
+			if binding != "" && refers(clause, binding) {
+				return
+			}
+		case isNil(clause.List[0]):
+			if binding != "" && refers(clause, binding) {
+				return
+			}
+			cond = fmt.Sprintf("%s == nil", r.text(err))
+		default:
+			cond = r.asCond(binding, clause.List[0], err)
+			asserted = true
+		}
+
+		start := r.offset(clause.Pos())
+		switch {
+		case i == 0:
+			start = r.offset(stmt.Pos())
+			edits = append(edits, edit.Edit{Start: start, End: r.offset(clause.Colon + 1), Text: "if " + cond + " {"})
+		case cond == "":
+			edits = append(edits, edit.Edit{Start: start, End: r.offset(clause.Colon + 1), Text: "} else {"})
+		default:
+			edits = append(edits, edit.Edit{Start: start, End: r.offset(clause.Colon + 1), Text: "} else if " + cond + " {"})
+		}
+	}
+
+	if !asserted {
+		// Nothing to check with errors.As
+		return
+	}
+
+	r.edits = append(r.edits, edits...)
+	header := edits[0]
+	r.record(stmt, string(r.src[header.Start:header.End]), header.Text)
+}
+
+// ifAssertion rewrites `if v, ok := errors.Cause(err).(*T); ok {` into `if v := (*T)(nil); errors.As(err, &v) {`,
+// unless `ok` is referred within the statement.
+func (r *rewriter) ifAssertion(stmt *ast.IfStmt) {
+	assign, ok := stmt.Init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 {
+		return
+	}
+
+	cond, ok := stmt.Cond.(*ast.Ident)
+	okIdent, isIdent := assign.Lhs[1].(*ast.Ident)
+	if !ok || !isIdent || cond.Name != okIdent.Name || refers(stmt.Body, cond.Name) || (stmt.Else != nil && refers(stmt.Else, cond.Name)) {
+		return
+	}
+
+	assert, err := r.causeAssertion(assign)
+	if assert == nil {
+		return
+	}
+
+	text := r.asCond(identName(assign.Lhs[0]), assert.Type, err)
+	r.replace(assign, assign.Pos(), cond.End(), text)
+}
+
+// assertion rewrites `v, ok := errors.Cause(err).(T)` statement into `var v T` and `ok := errors.As(err, &v)`
+// statements on separate lines. The `:=` statement assigning any variable declared before is kept as is,
+// as the rewritten statements would declare it again.
+func (r *rewriter) assertion(stmt *ast.AssignStmt) {
+	if len(stmt.Lhs) != 2 || (stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN) {
+		return
+	}
+
+	assert, err := r.causeAssertion(stmt)
+	if assert == nil {
+		return
+	}
+
+	// Assertions in if statements are handled along with the statement
+	if r.inits[stmt] {
+		return
+	}
+	if stmt.Tok == token.DEFINE && (declaredBefore(stmt, stmt.Lhs[0]) || declaredBefore(stmt, stmt.Lhs[1])) {
+		return
+	}
+
+	v, ok := identName(stmt.Lhs[0]), r.text(stmt.Lhs[1])
+	target := "&" + v
+	text := ""
+	switch {
+	case v == "_":
+		target = fmt.Sprintf("new(%s)", r.text(assert.Type))
+	case stmt.Tok == token.DEFINE:
+		text = fmt.Sprintf("var %s %s\n%s", v, r.text(assert.Type), r.indentation(stmt))
+	}
+	text += fmt.Sprintf("%s %s %s.As(%s, %s)", ok, stmt.Tok, r.std, r.text(err), target)
+
+	r.replace(stmt, stmt.Pos(), stmt.End(), text)
+}
+
+// causeAssertion returns the two-value type assertion of `errors.Cause` result assigned by the statement,
+// along with the argument of `errors.Cause`.
+func (r *rewriter) causeAssertion(stmt *ast.AssignStmt) (*ast.TypeAssertExpr, ast.Expr) {
+	if len(stmt.Rhs) != 1 {
+		return nil, nil
+	}
+
+	assert, ok := stmt.Rhs[0].(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil || identName(stmt.Lhs[0]) == "" {
+		return nil, nil
+	}
+
+	err := r.causeArg(assert.X)
+	if err == nil {
+		return nil, nil
+	}
+
+	return assert, err
+}
+
+// asCond returns the condition checking whether err has the type in its chain using `errors.As`,
+// declaring the variable holding the found error if the binding is given.
+func (r *rewriter) asCond(binding string, typ ast.Expr, err ast.Expr) string {
+	if binding == "" || binding == "_" {
+		return fmt.Sprintf("%s.As(%s, new(%s))", r.std, r.text(err), r.text(typ))
+	}
+
+	zero := fmt.Sprintf("*new(%s)", r.text(typ))
+	if _, ok := typ.(*ast.StarExpr); ok {
+		zero = fmt.Sprintf("(%s)(nil)", r.text(typ))
+	}
+
+	return fmt.Sprintf("%s := %s; %s.As(%s, &%s)", binding, zero, r.std, r.text(err), binding)
+}
+
+// causeArg returns the argument of the expression if it's `errors.Cause` invocation.
+func (r *rewriter) causeArg(expr ast.Expr) ast.Expr {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return nil
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Cause" {
+		return nil
+	}
+
+	// Identifiers resolved to a local declaration are not package references
+	if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != r.name || ident.Obj != nil {
+		return nil
+	}
+
+	return call.Args[0]
+}
+
+// replace replaces src[start:end] with the text and records the result.
+func (r *rewriter) replace(node ast.Node, start, end token.Pos, text string) {
+	r.edits = append(r.edits, edit.Edit{Start: r.offset(start), End: r.offset(end), Text: text})
+	r.record(node, string(r.src[r.offset(start):r.offset(end)]), text)
+}
+
+func (r *rewriter) record(node ast.Node, original, rewritten string) {
+	pos := r.fset.Position(node.Pos())
+	r.calls = append(r.calls, common.Call{
+		Line:      pos.Line,
+		Column:    pos.Column,
+		Func:      "Cause",
+		Original:  original,
+		Rewritten: rewritten,
+		Status:    common.Migrated,
+		Matcher:   matcherName,
+	})
+}
+
+// indentation returns the leading whitespace of the line the node starts on.
+func (r *rewriter) indentation(node ast.Node) string {
+	tokFile := r.fset.File(node.Pos())
+	line := r.src[tokFile.Offset(tokFile.LineStart(tokFile.Line(node.Pos()))):r.offset(node.Pos())]
+
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+func (r *rewriter) text(node ast.Node) string {
+	return string(r.src[r.offset(node.Pos()):r.offset(node.End())])
+}
+
+// hasBreak reports whether any of the statements contains `break` referring to the enclosing statement.
+func hasBreak(stmts []ast.Stmt) bool {
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+				// Breaks inside refer to the nested statement
+				return false
+			case *ast.BranchStmt:
+				if n.Tok == token.BREAK && n.Label == nil {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+
+	return found
+}
+
+// refers reports whether the identifier is referred within the node.
+func refers(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// isVariable reports whether the expression is an identifier or a selector of identifiers, e.g. `resp.Err`,
+// so evaluating it several times has no side effects.
+func isVariable(expr ast.Expr) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isVariable(e.X)
+	default:
+		return false
+	}
+}
+
+// declaredBefore reports whether the identifier assigned by the `:=` statement refers to the variable
+// declared before the statement, rather than declares it.
+func declaredBefore(stmt *ast.AssignStmt, expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return true
+	}
+
+	return ident.Name != "_" && (ident.Obj == nil || ident.Obj.Decl != stmt)
+}
+
+func isNil(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+
+	return ""
+}
+
+// addImport imports the standard library `errors` package under the name.
+func addImport(src []byte, name string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	alias := name
+	if alias == "errors" {
+		alias = ""
+	}
+	astutil.AddNamedImport(fset, file, alias, stdErrorsImportPath)
+
+	buf := bytes.Buffer{}
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,282 @@
+package causes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/migrator/causes"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/resolver"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Comparisons are rewritten into errors.Is",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo(err error) bool {
+	if errors.Cause(err) == ErrNotFound {
+		return true
+	}
+	return ErrExists != errors.Cause(err)
+}
+`,
+			expected: `package foo
+
+import (
+	stderrors "errors"
+	"github.com/pkg/errors"
+)
+
+func foo(err error) bool {
+	if stderrors.Is(err, ErrNotFound) {
+		return true
+	}
+	return !stderrors.Is(err, ErrExists)
+}
+`,
+		},
+		{
+			name: "Existing standard library import is used",
+			input: `package foo
+
+import (
+	goerrors "errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) bool {
+	return pkgerrors.Cause(err) == goerrors.ErrUnsupported
+}
+`,
+			expected: `package foo
+
+import (
+	goerrors "errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) bool {
+	return goerrors.Is(err, goerrors.ErrUnsupported)
+}
+`,
+		},
+		{
+			name: "Type switch is rewritten into errors.As chain",
+			input: `package foo
+
+import pkgerrors "github.com/pkg/errors"
+
+func foo(err error) string {
+	switch e := pkgerrors.Cause(err).(type) {
+	case *NotFoundError:
+		return e.Name
+	case TimeoutError:
+		return e.Error()
+	case nil:
+		return "ok"
+	default:
+		return "unknown"
+	}
+}
+`,
+			expected: `package foo
+
+import (
+	"errors"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) string {
+	if e := (*NotFoundError)(nil); errors.As(err, &e) {
+		return e.Name
+	} else if e := *new(TimeoutError); errors.As(err, &e) {
+		return e.Error()
+	} else if err == nil {
+		return "ok"
+	} else {
+		return "unknown"
+	}
+}
+`,
+		},
+		{
+			name: "Type assertions are rewritten into errors.As",
+			input: `package foo
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) bool {
+	if e, ok := pkgerrors.Cause(err).(*NotFoundError); ok {
+		return e.Temporary()
+	}
+	_, ok := pkgerrors.Cause(err).(*TimeoutError)
+	if !ok {
+		e, ok := pkgerrors.Cause(err).(*ExistsError)
+		return ok && e.Temporary()
+	}
+	return ok
+}
+`,
+			expected: `package foo
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(err error) bool {
+	if e := (*NotFoundError)(nil); errors.As(err, &e) {
+		return e.Temporary()
+	}
+	ok := errors.As(err, new(*TimeoutError))
+	if !ok {
+		var e *ExistsError
+		ok := errors.As(err, &e)
+		return ok && e.Temporary()
+	}
+	return ok
+}
+`,
+		},
+		{
+			name: "Unsupported statements are kept as is",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo(err error) error {
+	switch errors.Cause(err).(type) {
+	case *NotFoundError, *TimeoutError:
+		return nil
+	}
+	for {
+		switch e := errors.Cause(err).(type) {
+		case *NotFoundError:
+			break
+		default:
+			return e
+		}
+	}
+	if e, ok := errors.Cause(err).(*NotFoundError); ok || e == nil {
+		return nil
+	}
+	ok := err == nil
+	e, ok := errors.Cause(err).(*NotFoundError)
+	timeout, ok := errors.Cause(err).(*TimeoutError)
+	if ok || timeout == nil || e == nil {
+		return nil
+	}
+	switch errors.Cause(check()).(type) {
+	case *NotFoundError:
+		return nil
+	case *TimeoutError:
+		return nil
+	}
+	return errors.Cause(err)
+}
+`,
+			expected: `package foo
+
+import "github.com/pkg/errors"
+
+func foo(err error) error {
+	switch errors.Cause(err).(type) {
+	case *NotFoundError, *TimeoutError:
+		return nil
+	}
+	for {
+		switch e := errors.Cause(err).(type) {
+		case *NotFoundError:
+			break
+		default:
+			return e
+		}
+	}
+	if e, ok := errors.Cause(err).(*NotFoundError); ok || e == nil {
+		return nil
+	}
+	ok := err == nil
+	e, ok := errors.Cause(err).(*NotFoundError)
+	timeout, ok := errors.Cause(err).(*TimeoutError)
+	if ok || timeout == nil || e == nil {
+		return nil
+	}
+	switch errors.Cause(check()).(type) {
+	case *NotFoundError:
+		return nil
+	case *TimeoutError:
+		return nil
+	}
+	return errors.Cause(err)
+}
+`,
+		},
+		{
+			name: "Files without pkg/errors are kept as is",
+			input: `package foo
+
+import "errors"
+
+func foo(err error) bool {
+	return errors.Unwrap(err) == ErrNotFound
+}
+`,
+			expected: `package foo
+
+import "errors"
+
+func foo(err error) bool {
+	return errors.Unwrap(err) == ErrNotFound
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := causes.Rewrite([]byte(tt.input), resolver.ErrorsImportPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestRewriteResults(t *testing.T) {
+	input := `package foo
+
+import "github.com/pkg/errors"
+
+func foo(err error) bool {
+	return errors.Cause(err) == ErrNotFound
+}
+`
+
+	_, calls, err := causes.Rewrite([]byte(input), resolver.ErrorsImportPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Call{{
+		Line:      6,
+		Column:    9,
+		Func:      "Cause",
+		Original:  "errors.Cause(err) == ErrNotFound",
+		Rewritten: "stderrors.Is(err, ErrNotFound)",
+		Status:    common.Migrated,
+		Matcher:   "RewriteCause",
+	}}, calls)
+}
@@ -0,0 +1,30 @@
+package matcher_common
+
+// Config configures the migration engines.
+type Config struct {
+	// TodoComment is appended to lines which have to be migrated manually.
+	// If empty, the lines are left as is and only reported.
+	TodoComment string
+	// Matchers are the extra parameter matchers by the function name, e.g. compiled from the rewrite rules.
+	// They are tried in front of the built-in ones.
+	Matchers map[string][]func([]string) []string
+	// FieldNames map the field names inferred from the messages to the ones to use instead, e.g. "ns" => "namespace".
+	FieldNames map[string]string
+	// Packages are the packages migrated between, the defaults are used for the empty fields.
+	Packages Packages
+}
+
+// DefaultConfig is the configuration used unless specified otherwise.
+var DefaultConfig = Config{
+	TodoComment: "// TODO: migrate manually",
+	Packages:    DefaultPackages,
+}
+
+// MarkTodo appends the TODO comment to the line, if enabled.
+func (c Config) MarkTodo(line string) string {
+	if c.TodoComment == "" {
+		return line
+	}
+
+	return line + " " + c.TodoComment
+}
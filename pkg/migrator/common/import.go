@@ -1,12 +1,86 @@
 package matcher_common
 
 import (
-	"strings"
+	"regexp"
+
+	"mig/pkg/migrator/resolver"
 )
 
-func MatchImport(line string) string {
-	if strings.Contains(line, "github.com/pkg/errors") {
-		return strings.ReplaceAll(line, "github.com/pkg/errors", "github.com/kanisterio/errkit")
+// ErrkitImportPath is the import path of the package being migrated to by default.
+const ErrkitImportPath = "github.com/kanisterio/errkit"
+
+// DefaultQualifier is the identifier the migrated invocations refer to the target package by default.
+const DefaultQualifier = "errkit"
+
+// Packages are the packages the migration is performed between, e.g. a fork of `github.com/pkg/errors`
+// or an internal mirror of errkit.
+type Packages struct {
+	// Source is the import path of the package being migrated from
+	Source string
+	// Target is the import path of the package being migrated to
+	Target string
+	// Qualifier is the identifier the migrated invocations refer to the target package by.
+	// The target package is imported under this name if it differs from the package name.
+	Qualifier string
+}
+
+// DefaultPackages migrate from `github.com/pkg/errors` to `github.com/kanisterio/errkit`.
+var DefaultPackages = Packages{
+	Source:    resolver.ErrorsImportPath,
+	Target:    ErrkitImportPath,
+	Qualifier: DefaultQualifier,
+}
+
+// WithDefaults returns the packages with the empty fields set to the defaults.
+// The qualifier defaults to the name of the target package, unless the target is the default one.
+func (p Packages) WithDefaults() Packages {
+	if p.Source == "" {
+		p.Source = DefaultPackages.Source
+	}
+	if p.Target == "" {
+		p.Target = DefaultPackages.Target
+	}
+	if p.Qualifier == "" {
+		p.Qualifier = resolver.PackageName(p.Target)
+	}
+
+	return p
+}
+
+// Alias returns the name the target package has to be imported by, or empty string if the qualifier
+// is the package name.
+func (p Packages) Alias() string {
+	if p.Qualifier == resolver.PackageName(p.Target) {
+		return ""
+	}
+
+	return p.Qualifier
+}
+
+// MatchImport replaces `github.com/pkg/errors` with `github.com/kanisterio/errkit`.
+// The import alias is dropped since migrated invocations always refer to the `errkit` package.
+var MatchImport = ImportMatcher(DefaultPackages)
+
+// ImportMatcher returns the handler replacing the source package import with the target one.
+// Only the import spec is replaced, other mentions of the source import path are kept as is.
+// The import alias is replaced by the one the target package requires, if any.
+func ImportMatcher(pkgs Packages) func(Line) Result {
+	pkgs = pkgs.WithDefaults()
+	// Matches the import spec of the source package with an optional alias, followed by nothing but
+	// the end of the import declaration or a comment
+	importRegex := regexp.MustCompile(`^(\s*(?:import\s+)?)(?:[\w.]+\s+)?"` + regexp.QuoteMeta(pkgs.Source) +
+		`"(\s*(?:[;)]\s*)?(?://.*|/\*.*)?)$`)
+	target := `"` + pkgs.Target + `"`
+	if alias := pkgs.Alias(); alias != "" {
+		target = alias + " " + target
+	}
+
+	return func(line Line) Result {
+		match := importRegex.FindStringSubmatch(line.Text)
+		if match == nil {
+			return Result{Text: line.Text}
+		}
+
+		return Result{Text: match[1] + target + match[2], Status: Migrated, Matcher: "MatchImport"}
 	}
-	return ""
 }
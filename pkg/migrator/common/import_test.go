@@ -0,0 +1,103 @@
+package matcher_common_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	common "mig/pkg/migrator/common"
+)
+
+func TestImportMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkgs     common.Packages
+		line     string
+		expected common.Result
+	}{
+		{
+			name:     "Default packages",
+			pkgs:     common.DefaultPackages,
+			line:     `import pkgerrors "github.com/pkg/errors"`,
+			expected: common.Result{Text: `import "github.com/kanisterio/errkit"`, Status: common.Migrated, Matcher: "MatchImport"},
+		},
+		{
+			name:     "Custom packages",
+			pkgs:     common.Packages{Source: "example.com/errors", Target: "example.com/errkit/v2"},
+			line:     `	"example.com/errors" // errors`,
+			expected: common.Result{Text: `	"example.com/errkit/v2" // errors`, Status: common.Migrated, Matcher: "MatchImport"},
+		},
+		{
+			name:     "Target imported under the qualifier",
+			pkgs:     common.Packages{Target: "example.com/errkit", Qualifier: "xerrkit"},
+			line:     `	"github.com/pkg/errors"`,
+			expected: common.Result{Text: `	xerrkit "example.com/errkit"`, Status: common.Migrated, Matcher: "MatchImport"},
+		},
+		{
+			name:     "Other import",
+			pkgs:     common.Packages{Source: "example.com/errors"},
+			line:     `import "github.com/pkg/errors"`,
+			expected: common.Result{Text: `import "github.com/pkg/errors"`},
+		},
+		{
+			name:     "Import path prefixed by the source",
+			pkgs:     common.DefaultPackages,
+			line:     `	"github.com/pkg/errorsx"`,
+			expected: common.Result{Text: `	"github.com/pkg/errorsx"`},
+		},
+		{
+			name:     "Import path nested in the source",
+			pkgs:     common.DefaultPackages,
+			line:     `	"github.com/pkg/errors/v2"`,
+			expected: common.Result{Text: `	"github.com/pkg/errors/v2"`},
+		},
+		{
+			name:     "Source mentioned in a comment",
+			pkgs:     common.DefaultPackages,
+			line:     `	// Compatible with "github.com/pkg/errors"`,
+			expected: common.Result{Text: `	// Compatible with "github.com/pkg/errors"`},
+		},
+		{
+			name:     "Source mentioned in a string",
+			pkgs:     common.DefaultPackages,
+			line:     `	const path = "github.com/pkg/errors"`,
+			expected: common.Result{Text: `	const path = "github.com/pkg/errors"`},
+		},
+		{
+			name:     "Source as a string in a list",
+			pkgs:     common.DefaultPackages,
+			line:     `	"github.com/pkg/errors",`,
+			expected: common.Result{Text: `	"github.com/pkg/errors",`},
+		},
+		{
+			name:     "Only the import path is replaced",
+			pkgs:     common.DefaultPackages,
+			line:     `import "github.com/pkg/errors" // replaces github.com/pkg/errors`,
+			expected: common.Result{Text: `import "github.com/kanisterio/errkit" // replaces github.com/pkg/errors`, Status: common.Migrated, Matcher: "MatchImport"},
+		},
+		{
+			name:     "Single line import declaration is left to the imports fix",
+			pkgs:     common.DefaultPackages,
+			line:     `import ( "fmt"; "github.com/pkg/errors" )`,
+			expected: common.Result{Text: `import ( "fmt"; "github.com/pkg/errors" )`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, common.ImportMatcher(tt.pkgs)(common.Line{Text: tt.line}))
+		})
+	}
+}
+
+func TestPackagesWithDefaults(t *testing.T) {
+	assert.Equal(t, common.DefaultPackages, common.Packages{}.WithDefaults())
+
+	pkgs := common.Packages{Source: "example.com/errors", Target: "example.com/internal/errkit/v2"}.WithDefaults()
+	assert.Equal(t, "errkit", pkgs.Qualifier)
+	assert.Equal(t, "", pkgs.Alias())
+
+	pkgs.Qualifier = "xerrkit"
+	assert.Equal(t, "xerrkit", pkgs.Alias())
+}
@@ -0,0 +1,16 @@
+package matcher_common
+
+import "mig/pkg/migrator/resolver"
+
+// Line is a line of a file passed to the line handlers.
+type Line struct {
+	// Text is the line without the line ending. If an invocation started on the line spans multiple lines,
+	// Text holds all of them joined by "\n".
+	Text string
+	// Number is the number of the (first) line in the file, starting from 1
+	Number int
+	// File describes how the `errors` package is referred to in the file.
+	// It's nil if the file was not resolved, in which case the V2 matcher considers every `errors.` invocation,
+	// while the V1 one leaves the line untouched.
+	File *resolver.File
+}
@@ -0,0 +1,84 @@
+package matcher_common
+
+// Status is the outcome of handling a line or an invocation.
+// Statuses are ordered by severity, so the status of a file is the maximum of its lines statuses.
+type Status int
+
+const (
+	// Unchanged means there was nothing to migrate
+	Unchanged Status = iota
+	// Migrated means the code was rewritten
+	Migrated
+	// NeedsManual means the code was found, but it can't be migrated automatically
+	NeedsManual
+)
+
+func (s Status) String() string {
+	switch s {
+	case Unchanged:
+		return "unchanged"
+	case Migrated:
+		return "migrated"
+	case NeedsManual:
+		return "needs manual migration"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is the outcome of handling a line.
+type Result struct {
+	// Text is the resulting line
+	Text   string
+	Status Status
+	// Matcher is the name of the handler or matcher which produced the result
+	Matcher string
+	// Reason explains why the line needs manual migration
+	Reason string
+	// Calls are the outcomes of the invocations handled within the line, in the order of appearance.
+	// Handlers which don't track separate invocations leave it empty.
+	Calls []Call
+}
+
+// Call is the outcome of handling a single invocation or other piece of code, e.g. an import.
+type Call struct {
+	// Line and Column locate the original code, both start from 1, column is a byte offset
+	Line   int
+	Column int
+	// Func is the name of the invoked `errors` package function, empty for other code
+	Func string
+	// Original is the code before the migration
+	Original string
+	// Rewritten is the code after the migration, equal to Original unless the status is Migrated
+	Rewritten string
+	Status    Status
+	// Matcher is the name of the handler or matcher which produced the result
+	Matcher string
+	// Reason explains why the code needs manual migration
+	Reason string
+	// Sentinel is the name of the package-level variable initialized by the invocation, empty for other code
+	Sentinel string
+}
+
+// FileResult is the outcome of handling a whole file.
+type FileResult struct {
+	// Content is the resulting content of the file, nil if the file is unchanged
+	Content []byte
+	// Calls are the outcomes of the handled invocations, in the order of appearance
+	Calls []Call
+}
+
+// Status returns the most severe status of the calls.
+func (r FileResult) Status() Status {
+	return MaxStatus(r.Calls)
+}
+
+// MaxStatus returns the most severe status of the calls, Unchanged if there are none.
+func MaxStatus(calls []Call) Status {
+	status := Unchanged
+	for _, c := range calls {
+		status = max(status, c.Status)
+	}
+
+	return status
+}
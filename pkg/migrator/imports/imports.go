@@ -0,0 +1,183 @@
+package imports
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/resolver"
+)
+
+const fmtImportPath = "fmt"
+
+// StdErrorsAlias is the name the standard library `errors` package is imported by
+// while `github.com/pkg/errors` takes the `errors` name.
+const StdErrorsAlias = "stderrors"
+
+// Fix rewrites the import declarations of the migrated source, so the file compiles without running goimports:
+//   - `fmt` is imported if it's referred, e.g. by the generated `fmt.Sprintf` calls;
+//   - the target package, errkit by default, is imported exactly once if its qualifier is referred,
+//     and removed otherwise;
+//   - the source package, `github.com/pkg/errors` by default, is removed if it's no longer referred,
+//     or imported back under its original name if some invocations are left to be migrated manually;
+//   - the standard library `errors` imported as StdErrorsAlias takes the `errors` name back once it's free.
+//
+// The original source is used to find the name the source package was referred by.
+// If the imports are fine already, the migrated source is returned as is.
+func Fix(original, migrated []byte, pkgs common.Packages) ([]byte, error) {
+	pkgs = pkgs.WithDefaults()
+
+	fset := token.NewFileSet()
+	originalFile, err := parser.ParseFile(fset, "", original, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	_, errorsName := resolver.FindImport(originalFile, pkgs.Source)
+
+	file, err := parser.ParseFile(fset, "", migrated, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	referred := packageRefs(file)
+	changed := false
+
+	qualifier, alias := pkgs.Qualifier, pkgs.Alias()
+	switch count := countImports(file, pkgs.Target); {
+	case !referred[qualifier] && count > 0:
+		changed = astutil.DeleteNamedImport(fset, file, alias, pkgs.Target)
+	case referred[qualifier] && count > 1:
+		// Duplicates are dropped altogether, then the package is imported once again
+		astutil.DeleteNamedImport(fset, file, alias, pkgs.Target)
+		changed = astutil.AddNamedImport(fset, file, alias, pkgs.Target)
+	case referred[qualifier] && count == 0 && !named(file, qualifier):
+		changed = astutil.AddNamedImport(fset, file, alias, pkgs.Target)
+	}
+
+	if errorsName != "" {
+		spec, name := resolver.FindImport(file, pkgs.Source)
+		switch {
+		case spec != nil && !referred[name]:
+			changed = astutil.DeleteNamedImport(fset, file, importName(spec), pkgs.Source) || changed
+		case spec == nil && referred[errorsName] && !named(file, errorsName):
+			alias := errorsName
+			if alias == resolver.PackageName(pkgs.Source) {
+				alias = ""
+			}
+			changed = astutil.AddNamedImport(fset, file, alias, pkgs.Source) || changed
+		}
+	}
+
+	if referred["fmt"] && !named(file, "fmt") {
+		changed = astutil.AddImport(fset, file, fmtImportPath) || changed
+	}
+
+	changed = unaliasStdErrors(file, referred) || changed
+
+	if !changed {
+		return migrated, nil
+	}
+
+	buf := bytes.Buffer{}
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unaliasStdErrors drops the StdErrorsAlias name of the standard library `errors` import
+// and renames its references, if nothing else is referred by the `errors` name.
+func unaliasStdErrors(file *ast.File, referred map[string]bool) bool {
+	if referred["errors"] || named(file, "errors") {
+		return false
+	}
+
+	var spec *ast.ImportSpec
+	for _, s := range file.Imports {
+		if p, err := strconv.Unquote(s.Path.Value); err == nil && p == "errors" && importName(s) == StdErrorsAlias {
+			spec = s
+		}
+	}
+	if spec == nil {
+		return false
+	}
+
+	spec.Name = nil
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil && ident.Name == StdErrorsAlias {
+				ident.Name = "errors"
+			}
+		}
+		return true
+	})
+
+	return true
+}
+
+// packageRefs returns the names of the packages referred by selectors in the file.
+// Identifiers which aren't resolved within the file are considered to be package names.
+func packageRefs(file *ast.File) map[string]bool {
+	refs := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				refs[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	return refs
+}
+
+// countImports returns the number of specs importing the package.
+func countImports(file *ast.File, importPath string) int {
+	count := 0
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == importPath {
+			count++
+		}
+	}
+
+	return count
+}
+
+// named reports whether any import of the file is referred by the name.
+func named(file *ast.File, name string) bool {
+	for _, spec := range file.Imports {
+		if referredAs(spec) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// referredAs returns the name the imported package is referred by.
+// The package name is assumed from the import path.
+func referredAs(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+
+	p, _ := strconv.Unquote(spec.Path.Value)
+
+	return resolver.PackageName(p)
+}
+
+// importName returns the explicit name of the import, or empty string.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return ""
+	}
+
+	return spec.Name.Name
+}
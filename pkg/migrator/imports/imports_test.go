@@ -0,0 +1,316 @@
+package imports_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/imports"
+)
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		migrated string
+		expected string
+	}{
+		{
+			name: "fmt is imported for generated Sprintf",
+			original: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Errorf("%s and %s", a, b)
+}
+`,
+			migrated: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.New(fmt.Sprintf("%s and %s", a, b))
+}
+`,
+			expected: `package foo
+
+import (
+	"fmt"
+	"github.com/kanisterio/errkit"
+)
+
+func foo() error {
+	return errkit.New(fmt.Sprintf("%s and %s", a, b))
+}
+`,
+		},
+		{
+			name: "Duplicate errkit import is dropped",
+			original: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+	"github.com/pkg/errors"
+)
+
+func foo() error {
+	return errors.New("Not found")
+}
+`,
+			migrated: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+	"github.com/kanisterio/errkit"
+)
+
+func foo() error {
+	return errkit.New("Not found")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.New("Not found")
+}
+`,
+		},
+		{
+			name: "Unused pkg/errors import is removed",
+			original: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+	"github.com/pkg/errors"
+)
+
+func foo() error {
+	return errors.New("Not found")
+}
+`,
+			migrated: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+	"github.com/pkg/errors"
+)
+
+func foo() error {
+	return errkit.New("Not found")
+}
+`,
+			expected: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+)
+
+func foo() error {
+	return errkit.New("Not found")
+}
+`,
+		},
+		{
+			name: "pkg/errors is imported back for invocations left to be migrated manually",
+			original: `package foo
+
+import pkgerrors "github.com/pkg/errors"
+
+func foo() error {
+	if err != nil {
+		return pkgerrors.Wrapf(err, "%s %s", a, b)
+	}
+	return pkgerrors.New("Not found")
+}
+`,
+			migrated: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	if err != nil {
+		return pkgerrors.Wrapf(err, "%s %s", a, b) // TODO: migrate manually
+	}
+	return errkit.New("Not found")
+}
+`,
+			expected: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo() error {
+	if err != nil {
+		return pkgerrors.Wrapf(err, "%s %s", a, b) // TODO: migrate manually
+	}
+	return errkit.New("Not found")
+}
+`,
+		},
+		{
+			name: "Unused errkit import is replaced when nothing is migrated",
+			original: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Cause(err)
+}
+`,
+			migrated: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errors.Cause(err) // TODO: migrate manually
+}
+`,
+			expected: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Cause(err) // TODO: migrate manually
+}
+`,
+		},
+		{
+			name: "Standard library errors alias is dropped once pkg/errors is removed",
+			original: `package foo
+
+import (
+	stderrors "errors"
+
+	"github.com/pkg/errors"
+)
+
+func foo(err error) error {
+	if stderrors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return errors.Wrap(err, "Not found")
+}
+`,
+			migrated: `package foo
+
+import (
+	stderrors "errors"
+
+	"github.com/kanisterio/errkit"
+)
+
+func foo(err error) error {
+	if stderrors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return errkit.Wrap(err, "Not found")
+}
+`,
+			expected: `package foo
+
+import (
+	"errors"
+
+	"github.com/kanisterio/errkit"
+)
+
+func foo(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return errkit.Wrap(err, "Not found")
+}
+`,
+		},
+		{
+			name: "Fine imports are kept as is",
+			original: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.New("Not found")
+}
+`,
+			migrated: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+  return errkit.New("Not found")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+  return errkit.New("Not found")
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := imports.Fix([]byte(tt.original), []byte(tt.migrated), common.DefaultPackages)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestFixInvalidSource(t *testing.T) {
+	_, err := imports.Fix([]byte("package foo\n"), []byte("package foo\n\nfunc foo() {\n"), common.DefaultPackages)
+	assert.Error(t, err)
+}
+
+func TestFixCustomPackages(t *testing.T) {
+	original := `package foo
+
+import "example.com/errors"
+
+func foo() error {
+	return errors.New("Not found")
+}
+`
+	migrated := `package foo
+
+import "example.com/errors"
+
+func foo(err error) error {
+	if err != nil {
+		return errors.Wrap(err, "%s", bar) // TODO: migrate manually
+	}
+	return xerrkit.New("Not found")
+}
+`
+
+	result, err := imports.Fix([]byte(original), []byte(migrated), common.Packages{
+		Source:    "example.com/errors",
+		Target:    "example.com/internal/errkit/v2",
+		Qualifier: "xerrkit",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `package foo
+
+import (
+	"example.com/errors"
+	xerrkit "example.com/internal/errkit/v2"
+)
+
+func foo(err error) error {
+	if err != nil {
+		return errors.Wrap(err, "%s", bar) // TODO: migrate manually
+	}
+	return xerrkit.New("Not found")
+}
+`, string(result))
+}
@@ -1,17 +1,33 @@
 package matcher_v1
 
-import "strings"
+import (
+	"regexp"
+	"strings"
 
-func MatchSimpleErrorsNew(line string) string {
-	if !strings.Contains(line, "errors.New") {
-		return ""
-	}
+	"mig/pkg/migrator/registry"
+)
+
+var errorsNewMatchers = []registry.Matcher{
+	{
+		Name:     "ErrorsNew",
+		Priority: PriorityErrorsNew,
+		Literal:  "errors.New",
+		Pattern:  regexp.MustCompile(`^.*errors\.New.*$`),
+		Rewrite: func(match []string) string {
+			line := match[0]
+			replaced := strings.Replace(line, "errors.New", "errkit.New", -1)
 
-	replaced := strings.Replace(line, "errors.New", "errkit.New", -1)
+			if strings.Contains(line, "fmt.Sprintf") {
+				replaced = replaced + " // TODO: Fixme"
+			}
 
-	if strings.Contains(line, "fmt.Sprintf") {
-		replaced = replaced + " // TODO: Fixme"
-	}
+			return replaced
+		},
+	},
+}
 
-	return replaced
+var errorsNewRegistry = registry.New(errorsNewMatchers...)
+
+func MatchSimpleErrorsNew(line string) string {
+	return errorsNewRegistry.Rewrite(line)
 }
@@ -1,7 +1,41 @@
 package matcher_v1
 
+import (
+	"slices"
+	"strings"
+
+	"mig/pkg/migrator/registry"
+)
+
 type Matcher func(line string) string
 
+// Priorities of the matcher groups, the matchers of a group are tried in the order of the priorities within the group
+const (
+	PriorityErrorf     = 500
+	PriorityWrapf      = 400
+	PriorityStderr     = 300
+	PrioritySimpleWrap = 200
+	PriorityErrorsNew  = 100
+)
+
+// Registry holds all the matchers, the first one rewriting the line wins.
+var Registry = registry.New(slices.Concat(
+	errorfMatchers,
+	wrapfMatchers,
+	stderrMatchers,
+	simpleWrapMatchers,
+	errorsNewMatchers,
+)...)
+
+// AllMatchers are the matcher groups in the order they are tried
+var AllMatchers = []Matcher{
+	MatchErrorfWithNamedParams,
+	MatchWrapfWithNamedParams,
+	MatchWrapfStderr,
+	MatchSimpleWraps,
+	MatchSimpleErrorsNew,
+}
+
 func MatchSequentially(matchers []Matcher, line string) string {
 	for _, m := range matchers {
 		r := m(line)
@@ -14,9 +48,8 @@ func MatchSequentially(matchers []Matcher, line string) string {
 }
 
 func maybeTrimComma(msg string) string {
-	l := len(msg) - 1
-	if msg[l:] == "," || msg[l:] == ":" {
-		return msg[:l]
+	if strings.HasSuffix(msg, ",") || strings.HasSuffix(msg, ":") {
+		return msg[:len(msg)-1]
 	}
 
 	return msg
@@ -1,9 +1,12 @@
 package matcher_v1_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/frankban/quicktest"
+
+	"mig/pkg/migrator/matcher_v1"
 )
 
 func matchAll(line string) string {
@@ -29,36 +32,36 @@ func TestMatchers(t *testing.T) {
 		//	in:      "\t\"github.com/pkg/errors\"",
 		//	out:     "\t\"github.com/kanisterio/errkit\"",
 		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.New"),
-		//	in:      "errors.New(\"foo\")",
-		//	out:     "errkit.New(\"foo\")",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.New with fmt.Sprintf"),
-		//	in:      "errors.New(fmt.Sprintf(\"foo\"))",
-		//	out:     "errkit.New(fmt.Sprintf(\"foo\")) // TODO: Fixme",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrap"),
-		//	in:      "errors.Wrap(err, \"foo\")",
-		//	out:     "errkit.Wrap(err, \"foo\")",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrap with fmt.Sprintf"),
-		//	in:      "errors.Wrap(err, fmt.Sprintf(\"foo\"))",
-		//	out:     "errkit.Wrap(err, fmt.Sprintf(\"foo\")) // TODO: Fixme",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf"),
-		//	in:      "errors.Wrapf(err, \"foo\")",
-		//	out:     "errkit.Wrap(err, \"foo\")",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with fmt.Sprintf"),
-		//	in:      "errors.Wrapf(err, fmt.Sprintf(\"foo\"))",
-		//	out:     "errkit.Wrap(err, fmt.Sprintf(\"foo\")) // TODO: Fixme",
-		//},
+		{
+			comment: quicktest.Commentf("Simple errors.New"),
+			in:      "errors.New(\"foo\")",
+			out:     "errkit.New(\"foo\")",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.New with fmt.Sprintf"),
+			in:      "errors.New(fmt.Sprintf(\"foo\"))",
+			out:     "errkit.New(fmt.Sprintf(\"foo\")) // TODO: Fixme",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrap"),
+			in:      "errors.Wrap(err, \"foo\")",
+			out:     "errkit.Wrap(err, \"foo\")",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrap with fmt.Sprintf"),
+			in:      "errors.Wrap(err, fmt.Sprintf(\"foo\"))",
+			out:     "errkit.Wrap(err, fmt.Sprintf(\"foo\")) // TODO: Fixme",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf"),
+			in:      "errors.Wrapf(err, \"foo\")",
+			out:     "errkit.Wrap(err, \"foo\")",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with fmt.Sprintf"),
+			in:      "errors.Wrapf(err, fmt.Sprintf(\"foo\"))",
+			out:     "errkit.Wrap(err, fmt.Sprintf(\"foo\")) // TODO: Fixme",
+		},
 		//{
 		//	comment: quicktest.Commentf("Simple errors.Wrapf with stderr 1"),
 		//	in:      "errors.Wrapf(err, \"foo %s\", stderr)",
@@ -104,41 +107,41 @@ func TestMatchers(t *testing.T) {
 		//	in:      "errors.Wrapf(err, \"Error %s, foo.\", stderr)",
 		//	out:     "errkit.Wrap(err, \"Error foo.\", \"stderr\", stderr)",
 		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with stderr and app name 1"),
-		//	in:      "errors.Wrapf(err, \"Error foo: %s, app: %s\", stderr, a.name)",
-		//	out:     "errkit.Wrap(err, \"Error foo\", \"stderr\", stderr, \"app\", a.name)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with stderr and app name 2"),
-		//	in:      "\treturn errors.Wrapf(err, \"foo. %s app=%s\", stderr, cb.name)",
-		//	out:     "\treturn errkit.Wrap(err, \"foo\", \"stderr\", stderr, \"app\", cb.name)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with stderr and stdout"),
-		//	in:      "\treturn errors.Wrapf(err, \"Error %s, foo. stdout is %s\", stderr, stdout)",
-		//	out:     "\treturn errkit.Wrap(err, \"Error foo.\", \"stdout\", stdout, \"stderr\", stderr)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("errors.Wrapf with one named parameter"),
-		//	in:      "\treturn nil, errors.Wrapf(err, \"Foo, volume_id: %s\", *csi.VolumeId)",
-		//	out:     "\treturn nil, errkit.Wrap(err, \"Foo\", \"volume_id\", *csi.VolumeId)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with multiple named parameters 1"),
-		//	in:      "\treturn errors.Wrapf(err, \"foo. app=%s name=%s\", a.name, a.dbSubnetGroup)",
-		//	out:     "\treturn errkit.Wrap(err, \"foo.\", \"app\", a.name, \"name\", a.dbSubnetGroup)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("Simple errors.Wrapf with multiple named parameters 2"),
-		//	in:      "\treturn errors.Wrapf(err, \"foo. app=%s chart=%s release=%s\", c.name, c.chart.Chart, c.chart.Release)",
-		//	out:     "\treturn errkit.Wrap(err, \"foo.\", \"app\", c.name, \"chart\", c.chart.Chart, \"release\", c.chart.Release)",
-		//},
-		//{
-		//	comment: quicktest.Commentf("errors.Errorf with multiple named parameters 1"),
-		//	in:      "\treturn nil, errors.Errorf(\"Foo: volume_id=%s result_count=%d\", id, len(vols))",
-		//	out:     "\treturn nil, errkit.New(\"Foo\", \"volume_id\", id, \"result_count\", len(vols))",
-		//},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with stderr and app name 1"),
+			in:      "errors.Wrapf(err, \"Error foo: %s, app: %s\", stderr, a.name)",
+			out:     "errkit.Wrap(err, \"Error foo\", \"stderr\", stderr, \"app\", a.name)",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with stderr and app name 2"),
+			in:      "\treturn errors.Wrapf(err, \"foo. %s app=%s\", stderr, cb.name)",
+			out:     "\treturn errkit.Wrap(err, \"foo\", \"stderr\", stderr, \"app\", cb.name)",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with stderr and stdout"),
+			in:      "\treturn errors.Wrapf(err, \"Error %s, foo. stdout is %s\", stderr, stdout)",
+			out:     "\treturn errkit.Wrap(err, \"Error foo.\", \"stdout\", stdout, \"stderr\", stderr)",
+		},
+		{
+			comment: quicktest.Commentf("errors.Wrapf with one named parameter"),
+			in:      "\treturn nil, errors.Wrapf(err, \"Foo, volume_id: %s\", *csi.VolumeId)",
+			out:     "\treturn nil, errkit.Wrap(err, \"Foo\", \"volume_id\", *csi.VolumeId)",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with multiple named parameters 1"),
+			in:      "\treturn errors.Wrapf(err, \"foo. app=%s name=%s\", a.name, a.dbSubnetGroup)",
+			out:     "\treturn errkit.Wrap(err, \"foo.\", \"app\", a.name, \"name\", a.dbSubnetGroup)",
+		},
+		{
+			comment: quicktest.Commentf("Simple errors.Wrapf with multiple named parameters 2"),
+			in:      "\treturn errors.Wrapf(err, \"foo. app=%s chart=%s release=%s\", c.name, c.chart.Chart, c.chart.Release)",
+			out:     "\treturn errkit.Wrap(err, \"foo.\", \"app\", c.name, \"chart\", c.chart.Chart, \"release\", c.chart.Release)",
+		},
+		{
+			comment: quicktest.Commentf("errors.Errorf with multiple named parameters 1"),
+			in:      "\treturn nil, errors.Errorf(\"Foo: volume_id=%s result_count=%d\", id, len(vols))",
+			out:     "\treturn nil, errkit.New(\"Foo\", \"volume_id\", id, \"result_count\", len(vols))",
+		},
 		{
 			comment: quicktest.Commentf("errors.Errorf with multiple named parameters 2"),
 			in:      "\treturn nil, errors.Errorf(\"Required volume fields not available, volumeType: %s, Az: %s, VolumeTags: %v\", snapshot.Volume.VolumeType, snapshot.Volume.Az, snapshot.Volume.Tags)",
@@ -154,3 +157,38 @@ func TestMatchers(t *testing.T) {
 		})
 	}
 }
+
+// lines is a sample of the migrated code, most of the lines don't match any matcher
+var lines = []string{
+	"func (c *client) Delete(ctx context.Context, name string) error {",
+	"\tif err := c.api.Delete(ctx, name); err != nil {",
+	"\t\treturn errors.Wrapf(err, \"Failed to delete snapshot, volume_id: %s\", name)",
+	"\t}",
+	"\treturn nil, errors.Errorf(\"Required volume fields not available, volumeType: %s, Az: %s\", v.Type, v.Az)",
+	"\treturn errors.Wrapf(err, \"Failed to ping the application. Error:%s\", stderr)",
+	"\treturn errors.New(\"Not implemented\")",
+	"}",
+}
+
+// BenchmarkRegistry measures the per-line cost of the matchers, compiled once by the registry
+// and compiled on every line as they used to be.
+func BenchmarkRegistry(b *testing.B) {
+	b.Run("precompiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			matcher_v1.Registry.Match(lines[i%len(lines)])
+		}
+	})
+
+	b.Run("compiled per line", func(b *testing.B) {
+		matchers := matcher_v1.Registry.Matchers()
+		for i := 0; i < b.N; i++ {
+			line := lines[i%len(lines)]
+			for _, m := range matchers {
+				match := regexp.MustCompile(m.Pattern.String()).FindStringSubmatch(line)
+				if match != nil && m.Rewrite(match) != "" {
+					break
+				}
+			}
+		}
+	})
+}
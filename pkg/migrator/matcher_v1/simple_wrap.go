@@ -4,43 +4,47 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-)
-
-func matchWrapf(line string) string {
-	simpleWrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*)\)(.*)`)
-	match := simpleWrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[2], "%") {
-		return ""
-	}
 
-	replaced := fmt.Sprintf("%serrkit.Wrap(%s)%s", match[1], match[2], match[3])
-	if strings.Contains(match[2], "fmt.Sprintf") {
-		replaced = replaced + " // TODO: Fixme"
-	}
+	"mig/pkg/migrator/registry"
+)
 
-	return replaced
+var simpleWrapMatchers = []registry.Matcher{
+	{
+		Name:     "Wrap",
+		Priority: PrioritySimpleWrap + 1,
+		Literal:  "errors.Wrap(",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrap\((.*)\)(.*)`),
+		Rewrite: func(match []string) string {
+			replaced := fmt.Sprintf("%serrkit.Wrap(%s)%s", match[1], match[2], match[3])
+			if strings.Contains(match[2], "fmt.Sprintf") {
+				replaced = replaced + " // TODO: Fixme"
+			}
+
+			return replaced
+		},
+	},
+	{
+		Name:     "Wrapf",
+		Priority: PrioritySimpleWrap,
+		Literal:  "errors.Wrapf(",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*)\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[2], "%") {
+				return ""
+			}
+
+			replaced := fmt.Sprintf("%serrkit.Wrap(%s)%s", match[1], match[2], match[3])
+			if strings.Contains(match[2], "fmt.Sprintf") {
+				replaced = replaced + " // TODO: Fixme"
+			}
+
+			return replaced
+		},
+	},
 }
 
-func matchWrap(line string) string {
-	simpleWrap := regexp.MustCompile(`(.*)errors.Wrap\((.*)\)(.*)`)
-
-	match := simpleWrap.FindStringSubmatch(line)
-	replaced := ""
-	if match != nil {
-		replaced = fmt.Sprintf("%serrkit.Wrap(%s)%s", match[1], match[2], match[3])
-		if strings.Contains(match[2], "fmt.Sprintf") {
-			replaced = replaced + " // TODO: Fixme"
-		}
-	}
-
-	return replaced
-}
+var simpleWrapRegistry = registry.New(simpleWrapMatchers...)
 
 func MatchSimpleWraps(line string) string {
-	replaced := matchWrap(line)
-	if replaced != "" {
-		return replaced
-	}
-
-	return matchWrapf(line)
+	return simpleWrapRegistry.Rewrite(line)
 }
@@ -4,90 +4,83 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-)
-
-// example
-// return errors.Wrapf(err, "Error %s, resetting the mongodb application. stdout is %s", stderr, stdout)
-func matchWrapfStderrStdout(line string) string {
-	wrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*?)\"Error\s+\%s(?:[:,]?)\s*(.*?)\s*stdout is \%s\",\s*stderr,\s*stdout\)(.*)`)
-	match := wrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[3], "%") || strings.Contains(match[3], "fmt.Sprintf") {
-		return ""
-	}
-
-	return fmt.Sprintf("%serrkit.Wrap(%s\"Error %s\", \"stdout\", stdout, \"stderr\", stderr)%s", match[1], match[2], match[3], match[4])
-}
-
-// example
-// return errors.Wrapf(err, "Error while Pinging the database: %s, app: %s", stderr, a.name)
-// return errors.Wrapf(err, "Failed to delete documents from default bucket. %s app=%s", stderr, cb.name)
-func matchWrapfStderr3(line string) string {
-	simpleWrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*?)[:.]\s*%s,?\s*app[:=]\s*%s\",\s*stderr\,\s*(.*)\)(.*)`)
-	match := simpleWrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[2], "%") || strings.Contains(match[2], "fmt.Sprintf") {
-		return ""
-	}
-
-	replaced := fmt.Sprintf("%serrkit.Wrap(%s\", \"stderr\", stderr, \"app\", %s)%s", match[1], match[2], match[3], match[4])
-	return replaced
-
-}
 
-// example
-// return errors.Wrapf(err, "Error %s: Resetting the application.", stderr)
-// return errors.Wrapf(err, "Error %s while pinging the database.", stderr)
-// return errors.Wrapf(err, "Error %s, deleting resources while reseting application.", stderr)
-
-func matchWrapfStderr2(line string) string {
-	simpleWrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*?)\"Error\s+\%s(?:[:,]?)(.*?)\",\s*stderr\)(.*)`)
-	match := simpleWrap.FindStringSubmatch(line)
-	if match == nil ||
-		strings.Contains(match[2], "%") ||
-		strings.Contains(match[2], "fmt.Sprintf") ||
-		strings.Contains(match[3], "%") ||
-		strings.Contains(match[3], "fmt.Sprintf") {
-		return ""
-	}
+	"mig/pkg/migrator/registry"
+)
 
-	replaced := fmt.Sprintf("%serrkit.Wrap(%s\"Error%s\", \"stderr\", stderr)%s", match[1], match[2], match[3], match[4])
-	return replaced
+var stderrMatchers = []registry.Matcher{
+	// examples
+	//
+	// return errors.Wrapf(err, "Failed to ping postgresql DB. %s", stderr)
+	// return errors.Wrapf(err, "Failed to ping the application. Error:%s", stderr)
+	{
+		Name:     "WrapfStderr1",
+		Priority: PriorityStderr + 4,
+		Literal:  "stderr",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*?)(?:\.\s*Error:|Error:|:|\.)?\s*\%s\s*\"\,\s*stderr\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[2], "%") || strings.Contains(match[2], "fmt.Sprintf") {
+				return ""
+			}
+
+			return fmt.Sprintf("%serrkit.Wrap(%s\", \"stderr\", stderr)%s", match[1], match[2], match[3])
+		},
+	},
+	// example
+	// return errors.Wrapf(err, "Error %s: Resetting the application.", stderr)
+	// return errors.Wrapf(err, "Error %s while pinging the database.", stderr)
+	// return errors.Wrapf(err, "Error %s, deleting resources while reseting application.", stderr)
+	{
+		Name:     "WrapfStderr2",
+		Priority: PriorityStderr + 3,
+		Literal:  "stderr",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*?)\"Error\s+\%s(?:[:,]?)(.*?)\",\s*stderr\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[2], "%") ||
+				strings.Contains(match[2], "fmt.Sprintf") ||
+				strings.Contains(match[3], "%") ||
+				strings.Contains(match[3], "fmt.Sprintf") {
+				return ""
+			}
+
+			return fmt.Sprintf("%serrkit.Wrap(%s\"Error%s\", \"stderr\", stderr)%s", match[1], match[2], match[3], match[4])
+		},
+	},
+	// example
+	// return errors.Wrapf(err, "Error while Pinging the database: %s, app: %s", stderr, a.name)
+	// return errors.Wrapf(err, "Failed to delete documents from default bucket. %s app=%s", stderr, cb.name)
+	{
+		Name:     "WrapfStderr3",
+		Priority: PriorityStderr + 2,
+		Literal:  "stderr",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*?)[:.]\s*%s,?\s*app[:=]\s*%s\",\s*stderr\,\s*(.*)\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[2], "%") || strings.Contains(match[2], "fmt.Sprintf") {
+				return ""
+			}
+
+			return fmt.Sprintf("%serrkit.Wrap(%s\", \"stderr\", stderr, \"app\", %s)%s", match[1], match[2], match[3], match[4])
+		},
+	},
+	// example
+	// return errors.Wrapf(err, "Error %s, resetting the mongodb application. stdout is %s", stderr, stdout)
+	{
+		Name:     "WrapfStderrStdout",
+		Priority: PriorityStderr + 1,
+		Literal:  "stdout",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*?)\"Error\s+\%s(?:[:,]?)\s*(.*?)\s*stdout is \%s\",\s*stderr,\s*stdout\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[3], "%") || strings.Contains(match[3], "fmt.Sprintf") {
+				return ""
+			}
+
+			return fmt.Sprintf("%serrkit.Wrap(%s\"Error %s\", \"stdout\", stdout, \"stderr\", stderr)%s", match[1], match[2], match[3], match[4])
+		},
+	},
 }
 
-// examples
-//
-// return errors.Wrapf(err, "Failed to ping postgresql DB. %s", stderr)
-// return errors.Wrapf(err, "Failed to ping the application. Error:%s", stderr)
-func matchWrapfStderr1(line string) string {
-	simpleWrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*?)(?:\.\s*Error:|Error:|:|\.)?\s*\%s\s*\"\,\s*stderr\)(.*)`)
-	match := simpleWrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[2], "%") || strings.Contains(match[2], "fmt.Sprintf") {
-		return ""
-	}
-
-	replaced := fmt.Sprintf("%serrkit.Wrap(%s\", \"stderr\", stderr)%s", match[1], match[2], match[3])
-	return replaced
-}
+var stderrRegistry = registry.New(stderrMatchers...)
 
 func MatchWrapfStderr(line string) string {
-	replaced := matchWrapfStderr1(line)
-	if replaced != "" {
-		return replaced
-	}
-
-	replaced = matchWrapfStderr2(line)
-	if replaced != "" {
-		return replaced
-	}
-
-	replaced = matchWrapfStderr3(line)
-	if replaced != "" {
-		return replaced
-	}
-
-	replaced = matchWrapfStderrStdout(line)
-	if replaced != "" {
-		return replaced
-	}
-
-	return ""
+	return stderrRegistry.Rewrite(line)
 }
@@ -5,68 +5,77 @@ import (
 	"regexp"
 	"strings"
 
+	"mig/pkg/migrator/registry"
 	"mig/pkg/util"
 )
 
-// example
-// return nil, errors.Wrapf(err, "Failed to create snapshot, volume_id: %s", *csi.VolumeId)
-func matchWrapfWithNamedParam(line string) string {
-	wrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*?), "(.*?)\s*([^.\s=]+)[:=]{1}\s*\%s\s*",\s*([^,)]+)\)(.*)`)
-	match := wrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[3], "%") {
-		return ""
-	}
+var wrapfMatchers = []registry.Matcher{
+	// example
+	// return errors.Wrapf(err, "Failed to install helm chart. app=%s chart=%s release=%s", c.name, c.chart.Chart, c.chart.Release)
+	{
+		Name:     "WrapfWithThreeNamedParams",
+		Priority: PriorityWrapf + 3,
+		Literal:  "errors.Wrapf",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\(((.*?), "(.*?)\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*",\s*([^,)]+),\s*([^,)]+),\s*([^,)]+))\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[4], "%") || strings.Contains(match[4], "fmt.Sprintf") {
+				return ""
+			}
 
-	msg := maybeTrimComma(match[3])
+			return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s, \"%s\", %s, \"%s\", %s)%s", match[1], match[3], match[4], match[5], match[8], match[6], match[9], match[7], match[10], match[11])
+		},
+	},
+	// example
+	// return errors.Wrapf(err, "Failed to delete subnet group. You may need to delete it manually. app=%s name=%s", a.name, a.dbSubnetGroup)
+	// return errors.Wrapf(err, "DiskCLient.CreateOrUpdate in VolumeCreateFromSnapshot, diskName: %s, snapshotID: %s", diskName, snapshot.ID)
+	{
+		Name:     "WrapfWithTwoNamedParams",
+		Priority: PriorityWrapf + 2,
+		Literal:  "errors.Wrapf",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\(((.*?), "(.*?)\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*",\s*([^,)]+),\s*([^,)]+))\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[4], "%") || strings.Contains(match[4], "fmt.Sprintf") {
+				return ""
+			}
 
-	return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s)%s", match[1], match[2], msg, match[4], match[5], match[6])
-}
-
-// example
-// return errors.Wrapf(err, "Failed to delete subnet group. You may need to delete it manually. app=%s name=%s", a.name, a.dbSubnetGroup)
-// return errors.Wrapf(err, "DiskCLient.CreateOrUpdate in VolumeCreateFromSnapshot, diskName: %s, snapshotID: %s", diskName, snapshot.ID)
-func matchWrapfWithTwoNamedParams(line string) string {
-	wrap := regexp.MustCompile(`(.*)errors.Wrapf\(((.*?), "(.*?)\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*",\s*([^,)]+),\s*([^,)]+))\)(.*)`)
-	match := wrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[4], "%") || strings.Contains(match[4], "fmt.Sprintf") {
-		return ""
-	}
+			return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s, \"%s\", %s)%s", match[1], match[3], match[4], match[5], match[7], match[6], match[8], match[9])
+		},
+	},
+	// example
+	// return nil, errors.Wrapf(err, "Failed to create snapshot, volume_id: %s", *csi.VolumeId)
+	{
+		Name:     "WrapfWithNamedParam",
+		Priority: PriorityWrapf + 1,
+		Literal:  "errors.Wrapf",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*?), "(.*?)\s*([^.\s=]+)[:=]{1}\s*\%s\s*",\s*([^,)]+)\)(.*)`),
+		Rewrite: func(match []string) string {
+			if strings.Contains(match[3], "%") {
+				return ""
+			}
 
-	return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s, \"%s\", %s)%s", match[1], match[3], match[4], match[5], match[7], match[6], match[8], match[9])
-}
+			msg := maybeTrimComma(match[3])
 
-// example
-// return errors.Wrapf(err, "Failed to install helm chart. app=%s chart=%s release=%s", c.name, c.chart.Chart, c.chart.Release)
-func matchWrapfWithThreeNamedParams(line string) string {
-	wrap := regexp.MustCompile(`(.*)errors.Wrapf\(((.*?), "(.*?)\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*([^.\s=]+)=\%s\s*",\s*([^,)]+),\s*([^,)]+),\s*([^,)]+))\)(.*)`)
-	match := wrap.FindStringSubmatch(line)
-	if match == nil || strings.Contains(match[4], "%") || strings.Contains(match[4], "fmt.Sprintf") {
-		return ""
-	}
+			return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s)%s", match[1], match[2], msg, match[4], match[5], match[6])
+		},
+	},
+	// example
+	// return errors.Wrapf(err, "Waiting on snapshot %v", snap)
+	// return nil, errors.Wrapf(err, "Snapshot %s did not complete", snapID)
+	{
+		Name:     "WrapfWithImplicitParam",
+		Priority: PriorityWrapf,
+		Literal:  "errors.Wrapf",
+		Pattern:  regexp.MustCompile(`(.*)errors.Wrapf\((.*),\s*"([^%]*?)(:?\s\%[a-z]{1})([^%]*)",\s*([a-zA-Z0-9.]+)\)(.*)`),
+		Rewrite: func(match []string) string {
+			paramName := util.GuessParamName(match[3], match[6])
 
-	return fmt.Sprintf("%serrkit.Wrap(%s, \"%s\", \"%s\", %s, \"%s\", %s, \"%s\", %s)%s", match[1], match[3], match[4], match[5], match[8], match[6], match[9], match[7], match[10], match[11])
+			return fmt.Sprintf("%serrkit.Wrap(%s, \"%s%s\", \"%s\", %s)%s", match[1], match[2], match[3], match[5], paramName, match[6], match[7])
+		},
+	},
 }
 
-// example
-// return errors.Wrapf(err, "Waiting on snapshot %v", snap)
-// return nil, errors.Wrapf(err, "Snapshot %s did not complete", snapID)
-func matchWrapfWithImplicitParam(line string) string {
-	wrap := regexp.MustCompile(`(.*)errors.Wrapf\((.*),\s*"([^%]*?)(:?\s\%[a-z]{1})([^%]*)",\s*([a-zA-Z0-9.]+)\)(.*)`)
-	match := wrap.FindStringSubmatch(line)
-	if match == nil {
-		return ""
-	}
-
-	paramName := util.GuessParamName(match[3], match[6])
-
-	return fmt.Sprintf("%serrkit.Wrap(%s, \"%s%s\", \"%s\", %s)%s", match[1], match[2], match[3], match[5], paramName, match[6], match[7])
-}
+var wrapfRegistry = registry.New(wrapfMatchers...)
 
 func MatchWrapfWithNamedParams(line string) string {
-	return MatchSequentially([]Matcher{
-		matchWrapfWithThreeNamedParams,
-		matchWrapfWithTwoNamedParams,
-		matchWrapfWithNamedParam,
-		matchWrapfWithImplicitParam,
-	}, line)
+	return wrapfRegistry.Rewrite(line)
 }
@@ -1,41 +1,182 @@
 package matcher_v2
 
 import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	common "mig/pkg/migrator/common"
 	"mig/pkg/migrator/matcher_v2/mutators"
 	"mig/pkg/migrator/matcher_v2/parser"
 )
 
-// Define the handler map using the HandlerFunc type
-var handlerMap = map[string]mutators.HandlerFunc{
-	"Wrap":   mutators.HandleWrap,
-	"Wrapf":  mutators.HandleWrapf,
-	"Errorf": mutators.HandleErrorf,
-	"New":    mutators.HandleNew,
+// Matcher migrates lines according to the configuration.
+type Matcher struct {
+	cfg      common.Config
+	handlers mutators.HandlerMap
+	// sentinelHandlers are used for the invocations declaring sentinel errors
+	sentinelHandlers mutators.HandlerMap
+}
+
+var defaultMatcher = New(common.DefaultConfig)
+
+// New returns the Matcher using the configuration.
+func New(cfg common.Config) *Matcher {
+	opts := mutators.NewOptions(cfg)
+	handlers := mutators.Handlers(opts)
+
+	return &Matcher{
+		cfg:              cfg,
+		handlers:         handlers,
+		sentinelHandlers: mutators.WithSentinelHandlers(handlers, mutators.Sentinels(opts)),
+	}
 }
 
 // HandleLine receives a line of code and returns a transformed line.
 // If `errors` package invocation found, it will either be replaced with `errkit` package invocation
 // or marked as to be migrated manually.
 func HandleLine(line string) string {
-	// Use parser.ParseLine to find and split the line
-	prefix, errorsPart, suffix, err := parser.ParseLine(line)
-	if err != nil {
-		// Handle unexpected error, return the original line
-		return line
+	return HandleSourceLine(common.Line{Text: line}).Text
+}
+
+// HandleSourceLine receives a line of a file and returns the result of its transformation
+// using the default configuration.
+func HandleSourceLine(line common.Line) common.Result {
+	return defaultMatcher.HandleSourceLine(line)
+}
+
+// HandleSourceLine receives a line of a file and returns the result of its transformation.
+// If the file is resolved, only invocations referring to the `github.com/pkg/errors` package are
+// transformed, otherwise the first `errors.` invocation is.
+// Invocations spanning multiple lines keep their line breaks between the arguments, the ones left
+// to be migrated manually get the TODO comment on their last line. Invocations with comments inside
+// or the ones which can't be parsed are always left to be migrated manually.
+func (m *Matcher) HandleSourceLine(line common.Line) common.Result {
+	if line.File == nil {
+		// Use parser.ParseLine to find and split the line
+		prefix, errorsPart, suffix, err := parser.ParseLine(line.Text)
+		if err != nil {
+			// Handle unexpected error, return the original line
+			return common.Result{Text: line.Text}
+		}
+		if errorsPart == "" {
+			// No 'errors' invocation found, return the line as is
+			return common.Result{Text: line.Text}
+		}
+
+		call := m.mutate(errorsPart, "errors", "")
+		call.Line, call.Column = line.Number, len(prefix)+1
+		result := common.Result{Status: call.Status, Matcher: call.Matcher, Reason: call.Reason, Calls: []common.Call{call}}
+		if call.Status == common.NeedsManual {
+			// Mark this line as to be migrated manually
+			result.Text = m.cfg.MarkTodo(line.Text)
+			return result
+		}
+
+		// Return the concatenated prefix, mutatedErrorsPart, and suffix
+		result.Text = prefix + call.Rewritten + suffix
+		return result
+	}
+
+	// Go through invocations backwards, so the offsets of preceding ones stay valid
+	result := common.Result{Text: line.Text}
+	calls := line.File.CallsIn(line.Number, line.Text)
+	for i := len(calls) - 1; i >= 0; i-- {
+		number, column := position(line, calls[i])
+		prefix, errorsPart, suffix, err := parser.ParseLineAt(result.Text, calls[i], line.File.Name)
+		var call common.Call
+		switch {
+		case err != nil:
+			call = unparsed(result.Text[calls[i]:], line.File.Name, fmt.Sprintf("can't be parsed: %v", err))
+		case errorsPart == "":
+			continue
+		case line.File.HasComments(number, column-1):
+			call = unparsed(errorsPart, line.File.Name, "has comments inside which can't be carried over")
+		default:
+			call = m.mutate(errorsPart, line.File.Name, line.File.Sentinel(number, column-1))
+		}
+		call.Line, call.Column = number, column
+		result.Calls = append([]common.Call{call}, result.Calls...)
+		if call.Status == common.Migrated {
+			result.Text = prefix + call.Rewritten + suffix
+		}
+
+		// Keep the details of the most severe outcome, the leftmost one if equal
+		if call.Status >= result.Status {
+			result.Status, result.Matcher, result.Reason = call.Status, call.Matcher, call.Reason
+		}
+	}
+
+	if result.Status == common.NeedsManual {
+		result.Text = m.cfg.MarkTodo(result.Text)
 	}
-	if errorsPart == "" {
-		// No 'errors' invocation found, return the line as is
-		return line
+
+	return result
+}
+
+// position returns the line number and the column of the offset within the line text.
+func position(line common.Line, offset int) (int, int) {
+	number := line.Number + strings.Count(line.Text[:offset], "\n")
+	lineStart := strings.LastIndexByte(line.Text[:offset], '\n') + 1
+
+	return number, offset - lineStart + 1
+}
+
+// mutate passes errorsPart referring to the pkg package to Mutator.
+// If the invocation initializes the sentinel variable, sentinel handlers take precedence.
+// The result is NeedsManual if no modification was made.
+func (m *Matcher) mutate(errorsPart string, pkg string, sentinel string) common.Call {
+	// Mutator expects the package to be referred as `errors`
+	original := errorsPart
+	errorsPart = "errors." + strings.TrimPrefix(errorsPart, pkg+".")
+	funcName, _, _ := strings.Cut(strings.TrimPrefix(errorsPart, "errors."), "(")
+	funcName = strings.TrimSpace(funcName)
+
+	call := common.Call{Func: funcName, Original: original, Rewritten: original, Status: common.NeedsManual, Sentinel: sentinel}
+
+	if _, exists := m.handlers[funcName]; !exists {
+		call.Reason = fmt.Sprintf("errors.%s is not supported", funcName)
+		return call
 	}
 
-	// Pass errorsPart to Mutator
-	mutatedErrorsPart := mutators.Mutator(errorsPart, handlerMap)
+	handlers := m.handlers
+	call.Matcher = "Handle" + funcName
+	if _, exists := mutators.SentinelHandlers[funcName]; exists && sentinel != "" {
+		handlers = m.sentinelHandlers
+		call.Matcher += "Sentinel"
+	}
 
+	mutatedErrorsPart := mutators.Mutator(errorsPart, handlers)
 	if mutatedErrorsPart == errorsPart {
-		// No modification made by Mutator, mark this line as to be migrated manually
-		return line + " // TODO: migrate manually"
+		call.Reason = fmt.Sprintf("arguments of errors.%s can't be migrated automatically", funcName)
+		return call
 	}
 
-	// Return the concatenated prefix, mutatedErrorsPart, and suffix
-	return prefix + mutatedErrorsPart + suffix
+	if strings.Contains(errorsPart, "\n") {
+		// Keep the line breaks between the arguments
+		if args, gaps, err := mutators.CallGaps(errorsPart); err == nil {
+			mutatedErrorsPart = mutators.Relayout(mutatedErrorsPart, args, gaps)
+		}
+	}
+
+	call.Rewritten, call.Status = mutatedErrorsPart, common.Migrated
+
+	return call
+}
+
+// unparsed returns the result of the invocation of the pkg package which can't be passed to Mutator,
+// so it has to be migrated manually for the reason given.
+func unparsed(errorsPart string, pkg string, reason string) common.Call {
+	funcName := strings.TrimPrefix(errorsPart, pkg+".")
+	if end := strings.IndexFunc(funcName, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }); end != -1 {
+		funcName = funcName[:end]
+	}
+
+	return common.Call{
+		Func:      funcName,
+		Original:  errorsPart,
+		Rewritten: errorsPart,
+		Status:    common.NeedsManual,
+		Reason:    fmt.Sprintf("invocation of errors.%s %s", funcName, reason),
+	}
 }
@@ -1,11 +1,14 @@
 package matcher_v2_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	common "mig/pkg/migrator/common"
 	matcher "mig/pkg/migrator/matcher_v2"
+	"mig/pkg/migrator/resolver"
 )
 
 func TestHandleLine(t *testing.T) {
@@ -89,3 +92,234 @@ func TestHandleLine(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleSourceLine(t *testing.T) {
+	src := `package foo
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo(errors Collector) error {
+	errors.Add(pkgerrors.Wrap(err, "Failed to add"))
+	if errors.Is(err, ErrNotFound) { return pkgerrors.New("Not found") }
+	return pkgerrors.Wrapf(err, "%s %s", errAccessingNode, n[0])
+}
+
+var ErrExists = pkgerrors.New("Exists")
+`
+	file, err := resolver.Resolve([]byte(src), resolver.ErrorsImportPath)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		line     int
+		expected common.Result
+	}{
+		{
+			name: "Local variable named errors is kept, aliased invocation is migrated",
+			line: 10,
+			expected: common.Result{
+				Text:    `	errors.Add(errkit.Wrap(err, "Failed to add"))`,
+				Status:  common.Migrated,
+				Matcher: "HandleWrap",
+				Calls: []common.Call{{
+					Line:      10,
+					Column:    13,
+					Func:      "Wrap",
+					Original:  `pkgerrors.Wrap(err, "Failed to add")`,
+					Rewritten: `errkit.Wrap(err, "Failed to add")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleWrap",
+				}},
+			},
+		},
+		{
+			name: "Standard library errors is kept",
+			line: 11,
+			expected: common.Result{
+				Text:    `	if errors.Is(err, ErrNotFound) { return errkit.New("Not found") }`,
+				Status:  common.Migrated,
+				Matcher: "HandleNew",
+				Calls: []common.Call{{
+					Line:      11,
+					Column:    42,
+					Func:      "New",
+					Original:  `pkgerrors.New("Not found")`,
+					Rewritten: `errkit.New("Not found")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleNew",
+				}},
+			},
+		},
+		{
+			name: "Corner case is marked as to be migrated manually",
+			line: 12,
+			expected: common.Result{
+				Text:    `	return pkgerrors.Wrapf(err, "%s %s", errAccessingNode, n[0]) // TODO: migrate manually`,
+				Status:  common.NeedsManual,
+				Matcher: "HandleWrapf",
+				Reason:  "arguments of errors.Wrapf can't be migrated automatically",
+				Calls: []common.Call{{
+					Line:      12,
+					Column:    9,
+					Func:      "Wrapf",
+					Original:  `pkgerrors.Wrapf(err, "%s %s", errAccessingNode, n[0])`,
+					Rewritten: `pkgerrors.Wrapf(err, "%s %s", errAccessingNode, n[0])`,
+					Status:    common.NeedsManual,
+					Matcher:   "HandleWrapf",
+					Reason:    "arguments of errors.Wrapf can't be migrated automatically",
+				}},
+			},
+		},
+		{
+			name: "Sentinel declaration is migrated to the sentinel constructor",
+			line: 15,
+			expected: common.Result{
+				Text:    `var ErrExists = errkit.NewSentinelErr("Exists")`,
+				Status:  common.Migrated,
+				Matcher: "HandleNewSentinel",
+				Calls: []common.Call{{
+					Line:      15,
+					Column:    17,
+					Func:      "New",
+					Original:  `pkgerrors.New("Exists")`,
+					Rewritten: `errkit.NewSentinelErr("Exists")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleNewSentinel",
+					Sentinel:  "ErrExists",
+				}},
+			},
+		},
+		{
+			name:     "Line without invocations is unchanged",
+			line:     9,
+			expected: common.Result{Text: `func foo(errors Collector) error {`},
+		},
+	}
+
+	lines := strings.Split(src, "\n")
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.HandleSourceLine(common.Line{Text: lines[tt.line-1], Number: tt.line, File: file})
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHandleSourceLineMultiLine(t *testing.T) {
+	src := `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	if err != nil {
+		return errors.Wrap(
+			errors.New("message"),
+			"wrapped")
+	}
+	return errors.Wrapf(err,
+		"%s %s", a, b)
+}
+
+func bar() error {
+	return errors.Wrapf(err, // the cause
+		"Failed to get PVC %s",
+		pvcName)
+}
+`
+	file, err := resolver.Resolve([]byte(src), resolver.ErrorsImportPath)
+	assert.NoError(t, err)
+
+	lines := strings.Split(src, "\n")
+	tests := []struct {
+		name     string
+		first    int
+		last     int
+		expected common.Result
+	}{
+		{
+			name:  "Migrated invocation keeps line breaks",
+			first: 7,
+			last:  9,
+			expected: common.Result{
+				Text:    "\t\treturn errkit.Wrap(\n\t\t\terrkit.New(\"message\"),\n\t\t\t\"wrapped\")",
+				Status:  common.Migrated,
+				Matcher: "HandleWrap",
+				Calls: []common.Call{
+					{
+						Line:      7,
+						Column:    10,
+						Func:      "Wrap",
+						Original:  "errors.Wrap(\n\t\t\terrkit.New(\"message\"),\n\t\t\t\"wrapped\")",
+						Rewritten: "errkit.Wrap(\n\t\t\terrkit.New(\"message\"),\n\t\t\t\"wrapped\")",
+						Status:    common.Migrated,
+						Matcher:   "HandleWrap",
+					},
+					{
+						Line:      8,
+						Column:    4,
+						Func:      "New",
+						Original:  `errors.New("message")`,
+						Rewritten: `errkit.New("message")`,
+						Status:    common.Migrated,
+						Matcher:   "HandleNew",
+					},
+				},
+			},
+		},
+		{
+			name:  "Manual invocation keeps line breaks",
+			first: 11,
+			last:  12,
+			expected: common.Result{
+				Text:    "\treturn errors.Wrapf(err,\n\t\t\"%s %s\", a, b) // TODO: migrate manually",
+				Status:  common.NeedsManual,
+				Matcher: "HandleWrapf",
+				Reason:  "arguments of errors.Wrapf can't be migrated automatically",
+				Calls: []common.Call{{
+					Line:      11,
+					Column:    9,
+					Func:      "Wrapf",
+					Original:  "errors.Wrapf(err,\n\t\t\"%s %s\", a, b)",
+					Rewritten: "errors.Wrapf(err,\n\t\t\"%s %s\", a, b)",
+					Status:    common.NeedsManual,
+					Matcher:   "HandleWrapf",
+					Reason:    "arguments of errors.Wrapf can't be migrated automatically",
+				}},
+			},
+		},
+		{
+			name:  "Invocation with comments inside is marked as to be migrated manually",
+			first: 16,
+			last:  18,
+			expected: common.Result{
+				Text:   "\treturn errors.Wrapf(err, // the cause\n\t\t\"Failed to get PVC %s\",\n\t\tpvcName) // TODO: migrate manually",
+				Status: common.NeedsManual,
+				Reason: "invocation of errors.Wrapf has comments inside which can't be carried over",
+				Calls: []common.Call{{
+					Line:      16,
+					Column:    9,
+					Func:      "Wrapf",
+					Original:  "errors.Wrapf(err, // the cause\n\t\t\"Failed to get PVC %s\",\n\t\tpvcName)",
+					Rewritten: "errors.Wrapf(err, // the cause\n\t\t\"Failed to get PVC %s\",\n\t\tpvcName)",
+					Status:    common.NeedsManual,
+					Reason:    "invocation of errors.Wrapf has comments inside which can't be carried over",
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.last, file.End(tt.first))
+			text := strings.Join(lines[tt.first-1:tt.last], "\n")
+			result := matcher.HandleSourceLine(common.Line{Text: text, Number: tt.first, File: file})
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
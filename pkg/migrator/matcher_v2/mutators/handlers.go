@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"strings"
 
+	common "mig/pkg/migrator/common"
 	"mig/pkg/migrator/matcher_v2/mutators/matcher"
 	"mig/pkg/migrator/matcher_v2/mutators/sanitizer"
 )
 
-type MatcherFn func([]string) []string
+// MatcherFn takes the arguments of the invocation following the wrapped error, if any, and returns them
+// transformed into the message followed by key/value pairs, or nil if they don't match.
+type MatcherFn = func([]string) []string
 
 var (
 	wrapMatchers = []MatcherFn{
@@ -16,44 +19,237 @@ var (
 	}
 	wrapfMatchers = []MatcherFn{
 		param_matcher.MatchSingleVariableAppend,
-		param_matcher.MatchOneVariableSimple,
-		param_matcher.MatchTwoVariablesSimple,
-		param_matcher.MatchTwoVariablesNoName,
-		param_matcher.MatchCurlyBracedTwoVariables,
+		param_matcher.MatchTemplate,
 	}
 	errorfMatchers = []MatcherFn{
 		param_matcher.MatchSingleVariableAppend,
-		param_matcher.MatchOneVariableSimple,
-		param_matcher.MatchTwoVariablesSimple,
-		param_matcher.MatchTwoVariablesNoName,
-		param_matcher.MatchCurlyBracedTwoVariables,
+		param_matcher.MatchTemplate,
 	}
 )
 
 // HandleWrap takes a slice of arguments and applies matchers to format the elements.
 // It returns the formatted error wrapping string.
-var HandleWrap = getWrapHandler(wrapMatchers)
-var HandleWrapf = getWrapHandler(wrapfMatchers)
-var HandleErrorf = getNewHandler(errorfMatchers)
-var HandleNew = getNewHandler([]MatcherFn{})
+var HandleWrap = getWrapHandler(common.DefaultQualifier, wrapMatchers)
+var HandleWrapf = getWrapfHandler(common.DefaultQualifier, wrapfMatchers)
+var HandleErrorf = getErrorfHandler(common.DefaultQualifier, errorfMatchers, []MatcherFn{})
+var HandleNew = getNewHandler(common.DefaultQualifier, []MatcherFn{})
 
-func getNewHandler(matchers []MatcherFn) func([]string) string {
+// HandleWithMessage and HandleWithMessagef annotate the error the same way Wrap does,
+// errkit.Wrap adds the stack trace on top of the message.
+var HandleWithMessage = getWrapHandler(common.DefaultQualifier, wrapMatchers)
+var HandleWithMessagef = getWrapfHandler(common.DefaultQualifier, wrapfMatchers)
+
+// HandleWithStack handles the errors.WithStack function.
+// For example, errors.WithStack(err) => errkit.WithStack(err)
+var HandleWithStack = getWithStackHandler(common.DefaultQualifier)
+
+// DefaultHandlers maps `errors` package functions to the handlers migrating them.
+var DefaultHandlers = HandlerMap{
+	"Wrap":   HandleWrap,
+	"Wrapf":  HandleWrapf,
+	"Errorf": HandleErrorf,
+	"New":    HandleNew,
+
+	"WithStack":    HandleWithStack,
+	"WithMessage":  HandleWithMessage,
+	"WithMessagef": HandleWithMessagef,
+}
+
+// MatchedFuncs are the functions which handlers try the matchers for.
+var MatchedFuncs = []string{"Wrap", "Wrapf", "WithMessage", "WithMessagef", "Errorf", "New"}
+
+// NewFuncs are the functions of MatchedFuncs creating new errors, which are migrated to New.
+// Their matchers take all the arguments of the invocation. Errorf wrapping the error with the %w verb
+// is migrated to Wrap by the built-in matchers only.
+var NewFuncs = []string{"Errorf", "New"}
+
+// Options customize the handlers.
+type Options struct {
+	// Matchers are the extra matchers by the function name, tried before the built-in ones.
+	// They are only tried for MatchedFuncs.
+	Matchers map[string][]MatcherFn
+	// FieldNames map the field names inferred by the built-in matchers to the ones to use instead.
+	FieldNames map[string]string
+	// Qualifier is the identifier the target package is referred by, common.DefaultQualifier if empty.
+	Qualifier string
+}
+
+// NewOptions returns the options customizing the handlers according to the configuration.
+func NewOptions(cfg common.Config) Options {
+	return Options{
+		Matchers:   cfg.Matchers,
+		FieldNames: cfg.FieldNames,
+		Qualifier:  cfg.Packages.WithDefaults().Qualifier,
+	}
+}
+
+// qualifier returns the identifier the target package is referred by.
+func (o Options) qualifier() string {
+	if o.Qualifier == "" {
+		return common.DefaultQualifier
+	}
+
+	return o.Qualifier
+}
+
+// Handlers returns the handlers customized with the options, DefaultHandlers if there is nothing to customize.
+func Handlers(opts Options) HandlerMap {
+	q := opts.qualifier()
+	if len(opts.Matchers) == 0 && len(opts.FieldNames) == 0 && q == common.DefaultQualifier {
+		return DefaultHandlers
+	}
+
+	matchers := func(funcName string, builtin []MatcherFn) []MatcherFn {
+		return prepend(opts.Matchers[funcName], renameFields(builtin, opts.FieldNames))
+	}
+
+	return HandlerMap{
+		"Wrap":   getWrapHandler(q, matchers("Wrap", wrapMatchers)),
+		"Wrapf":  getWrapfHandler(q, matchers("Wrapf", wrapfMatchers)),
+		"Errorf": getErrorfHandler(q, renameFields(errorfMatchers, opts.FieldNames), opts.Matchers["Errorf"]),
+		"New":    getNewHandler(q, opts.Matchers["New"]),
+
+		"WithStack":    getWithStackHandler(q),
+		"WithMessage":  getWrapHandler(q, matchers("WithMessage", wrapMatchers)),
+		"WithMessagef": getWrapfHandler(q, matchers("WithMessagef", wrapfMatchers)),
+	}
+}
+
+// renameFields returns the matchers renaming the keys of their results according to the names.
+func renameFields(matchers []MatcherFn, names map[string]string) []MatcherFn {
+	if len(names) == 0 {
+		return matchers
+	}
+
+	renamed := make([]MatcherFn, 0, len(matchers))
+	for _, matcher := range matchers {
+		renamed = append(renamed, func(input []string) []string {
+			result := matcher(input)
+			// The message is followed by key/value pairs
+			for i := 1; i < len(result); i += 2 {
+				if name, ok := names[strings.Trim(result[i], `"`)]; ok {
+					result[i] = `"` + name + `"`
+				}
+			}
+
+			return result
+		})
+	}
+
+	return renamed
+}
+
+// prepend returns the new slice of the extra matchers followed by the built-in ones.
+func prepend(extra, builtin []MatcherFn) []MatcherFn {
+	return append(append(make([]MatcherFn, 0, len(extra)+len(builtin)), extra...), builtin...)
+}
+
+// SentinelHandlers take precedence over the default ones for the invocations declaring sentinel errors,
+// i.e. initializing package-level variables. Sentinels are compared by identity, so they are created
+// by the dedicated errkit constructor.
+var SentinelHandlers = HandlerMap{
+	"New": HandleNewSentinel,
+}
+
+// HandleNewSentinel handles the errors.New function declaring a sentinel error.
+// For example, errors.New("not found") => errkit.NewSentinelErr("not found")
+var HandleNewSentinel = getNewSentinelHandler(common.DefaultQualifier)
+
+// Sentinels returns SentinelHandlers customized with the options.
+func Sentinels(opts Options) HandlerMap {
+	q := opts.qualifier()
+	if q == common.DefaultQualifier {
+		return SentinelHandlers
+	}
+
+	return HandlerMap{
+		"New": getNewSentinelHandler(q),
+	}
+}
+
+// WithSentinelHandlers returns the handlers with the sentinel ones taking precedence.
+func WithSentinelHandlers(handlers, sentinels HandlerMap) HandlerMap {
+	result := make(HandlerMap, len(handlers)+len(sentinels))
+	for name, handler := range handlers {
+		result[name] = handler
+	}
+	for name, handler := range sentinels {
+		result[name] = handler
+	}
+
+	return result
+}
+
+func getNewSentinelHandler(q string) func([]string) string {
+	return func(args []string) string {
+		if len(args) != 1 {
+			return ""
+		}
+
+		return fmt.Sprintf("%s.NewSentinelErr(%s)", q, sanitizer.SanitizeString(args[0]))
+	}
+}
+
+func getWithStackHandler(q string) func([]string) string {
+	return func(args []string) string {
+		if len(args) != 1 {
+			return ""
+		}
+
+		return fmt.Sprintf("%s.WithStack(%s)", q, args[0])
+	}
+}
+
+func getNewHandler(q string, matchers []MatcherFn) func([]string) string {
 	return func(args []string) string {
 		// If no arguments are provided, return an empty string
 		if len(args) == 0 {
 			return ""
 		}
 
+		for _, matcher := range matchers {
+			if matched := matcher(args); matched != nil {
+				return fmt.Sprintf("%s.New(%s)", q, sanitizer.SanitizeString(strings.Join(matched, ", ")))
+			}
+		}
+
 		// If single argument is provided, return the formatted error wrapping string
 		if len(args) == 1 {
-			return fmt.Sprintf("errkit.New(%s)", sanitizer.SanitizeString(args[0]))
+			return fmt.Sprintf("%s.New(%s)", q, sanitizer.SanitizeString(args[0]))
 		}
 
-		return fmt.Sprintf(`errkit.New(fmt.Sprintf(%s, %s))`, args[0], sanitizer.SanitizeString(strings.Join(args[1:], ", ")))
+		return fmt.Sprintf(`%s.New(fmt.Sprintf(%s, %s))`, q, args[0], sanitizer.SanitizeString(strings.Join(args[1:], ", ")))
 	}
 }
 
-func getWrapHandler(matchers []MatcherFn) func([]string) string {
+// getErrorfHandler returns the New handler trying newMatchers, which turns the invocation into Wrap
+// if the template contains the %w verb, so the wrapped error stays in the chain. The template consisting
+// of the %w verb only adds no message, so the invocation turns into WithStack.
+func getErrorfHandler(q string, matchers []MatcherFn, newMatchers []MatcherFn) func([]string) string {
+	handleNew := getNewHandler(q, newMatchers)
+	return func(args []string) string {
+		if len(args) == 0 || !param_matcher.HasWrapVerb(args[0]) {
+			return handleNew(args)
+		}
+
+		wrapped, params := param_matcher.MatchWrappedError(args)
+		if params == nil {
+			return ""
+		}
+
+		if len(params) == 1 && params[0] == `""` {
+			return fmt.Sprintf("%s.WithStack(%s)", q, wrapped)
+		}
+
+		if result := wrap(q, wrapped, params, matchers); result != "" {
+			return result
+		}
+
+		return fmt.Sprintf(`%s.Wrap(%s, fmt.Sprintf(%s, %s))`, q, wrapped, params[0], sanitizer.SanitizeString(strings.Join(params[1:], ", ")))
+	}
+}
+
+func getWrapHandler(q string, matchers []MatcherFn) func([]string) string {
 	return func(args []string) string {
 		// If no arguments are provided, return an empty string
 		if len(args) == 0 {
@@ -65,25 +261,45 @@ func getWrapHandler(matchers []MatcherFn) func([]string) string {
 			return ""
 		}
 
-		// Extract the first argument, which is the error variable (e.g., "err")
-		errVar := args[0]
-		// Extract the remaining arguments, which are the error message and variables
-		params := args[1:]
+		// The first argument is the error variable (e.g., "err"),
+		// the remaining arguments are the error message and variables
+		return wrap(q, args[0], args[1:], matchers)
+	}
+}
 
-		// Try matching the argument using available matchers
-		for _, matcher := range matchers {
-			// Each matcher expects a slice as input, so we wrap the current arg in a slice
-			matchedResult := matcher(params)
-			if matchedResult != nil {
-				return fmt.Sprintf("errkit.Wrap(%s, %s)", errVar, sanitizer.SanitizeString(strings.Join(matchedResult, ", ")))
+// getWrapfHandler returns the Wrap handler which also accepts the %w verb in the template,
+// as long as it refers to the wrapped error itself. errkit can't wrap two errors at once.
+func getWrapfHandler(q string, matchers []MatcherFn) func([]string) string {
+	handleWrap := getWrapHandler(q, matchers)
+	return func(args []string) string {
+		if len(args) > 1 && param_matcher.HasWrapVerb(args[1]) {
+			wrapped, params := param_matcher.MatchWrappedError(args[1:])
+			if params == nil || wrapped != args[0] {
+				return ""
 			}
+			args = append([]string{args[0]}, params...)
 		}
 
-		// If two arguments are provided, return the formatted error wrapping string
-		if len(args) == 2 {
-			return fmt.Sprintf("errkit.Wrap(%s, %s)", args[0], sanitizer.SanitizeString(args[1]))
+		return handleWrap(args)
+	}
+}
+
+// wrap returns Wrap invocation of the package referred by q wrapping errVar with the message and variables
+// from params, or an empty string if params can't be matched.
+func wrap(q, errVar string, params []string, matchers []MatcherFn) string {
+	// Try matching the argument using available matchers
+	for _, matcher := range matchers {
+		// Each matcher expects a slice as input, so we wrap the current arg in a slice
+		matchedResult := matcher(params)
+		if matchedResult != nil {
+			return fmt.Sprintf("%s.Wrap(%s, %s)", q, errVar, sanitizer.SanitizeString(strings.Join(matchedResult, ", ")))
 		}
+	}
 
-		return ""
+	// If only the message is provided, return the formatted error wrapping string
+	if len(params) == 1 {
+		return fmt.Sprintf("%s.Wrap(%s, %s)", q, errVar, sanitizer.SanitizeString(params[0]))
 	}
+
+	return ""
 }
@@ -70,6 +70,16 @@ func TestHandleWrapf(t *testing.T) {
 			args:     []string{"err", `"Invalid log level: "+v`},
 			expected: `errkit.Wrap(err, "Invalid log level", "level", v)`,
 		},
+		{
+			name:     "Wrapf with wrapped error repeated in template",
+			args:     []string{"err", `"Failed to get PVC %s: %w"`, "pvcName", "err"},
+			expected: `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+		},
+		{
+			name:     "Wrapf wrapping another error",
+			args:     []string{"err", `"Failed to get PVC: %w"`, "cause"},
+			expected: "",
+		},
 		{
 			name:     "Wrapf with variable message",
 			args:     []string{"err", "message"},
@@ -116,6 +126,41 @@ func TestHandleErrorf(t *testing.T) {
 			args:     []string{`"Failed to create content, Volumesnapshot: %s, Error: %v"`, "snap.GetName()", "err"},
 			expected: `errkit.New(fmt.Sprintf("Failed to create content, Volumesnapshot: %s, Error: %v", snap.GetName(), err))`,
 		},
+		{
+			name:     "Errorf wrapping error",
+			args:     []string{`"Failed to create session: %w"`, "err"},
+			expected: `errkit.Wrap(err, "Failed to create session")`,
+		},
+		{
+			name:     "Errorf wrapping error with parameter",
+			args:     []string{`"Failed to get PVC %s: %w"`, "pvcName", "err"},
+			expected: `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+		},
+		{
+			name:     "Errorf wrapping error with several parameters",
+			args:     []string{`"Failed to get pod. Namespace: %s, Name: %s: %w"`, "ns", "podName", "err"},
+			expected: `errkit.Wrap(err, "Failed to get pod.", "namespace", ns, "name", podName)`,
+		},
+		{
+			name:     "Errorf wrapping error with unmatched parameters",
+			args:     []string{`"Pod %s failed, pod: %s: %w"`, "name", "p.Name", "err"},
+			expected: `errkit.Wrap(err, fmt.Sprintf("Pod %s failed, pod: %s", name, p.Name))`,
+		},
+		{
+			name:     "Errorf wrapping error without message",
+			args:     []string{`"%w"`, "err"},
+			expected: `errkit.WithStack(err)`,
+		},
+		{
+			name:     "Errorf wrapping error with separator only",
+			args:     []string{`": %w"`, "err"},
+			expected: `errkit.WithStack(err)`,
+		},
+		{
+			name:     "Errorf wrapping multiple errors",
+			args:     []string{`"%w: %w"`, "err1", "err2"},
+			expected: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,3 +170,165 @@ func TestHandleErrorf(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWithStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "Simple WithStack",
+			args:     []string{"err"},
+			expected: `errkit.WithStack(err)`,
+		},
+		{
+			name:     "WithStack with expression",
+			args:     []string{"c.Close()"},
+			expected: `errkit.WithStack(c.Close())`,
+		},
+		{
+			name:     "WithStack without arguments",
+			args:     []string{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mutators.HandleWithStack(tt.args)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHandleNewSentinel(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "Simple sentinel",
+			args:     []string{`"not found"`},
+			expected: `errkit.NewSentinelErr("not found")`,
+		},
+		{
+			name:     "Sentinel with constant message",
+			args:     []string{"notFoundMsg"},
+			expected: `errkit.NewSentinelErr(notFoundMsg)`,
+		},
+		{
+			name:     "Sentinel without arguments",
+			args:     []string{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mutators.HandleNewSentinel(tt.args)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHandleWithMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "Simple WithMessage",
+			args:     []string{"err", `"Failed to create session"`},
+			expected: `errkit.Wrap(err, "Failed to create session")`,
+		},
+		{
+			name:     "WithMessage with concatenated string",
+			args:     []string{"err", `"failed to read env from dir:"+dir`},
+			expected: `errkit.Wrap(err, "failed to read env from dir", "dir", dir)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mutators.HandleWithMessage(tt.args)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHandleWithMessagef(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "Simple WithMessagef",
+			args:     []string{"err", `"Failed to create session"`},
+			expected: `errkit.Wrap(err, "Failed to create session")`,
+		},
+		{
+			name:     "WithMessagef with parameter",
+			args:     []string{"err", `"Failed to get PVC %s"`, "pvcName"},
+			expected: `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+		},
+		{
+			name:     "WithMessagef with unmatched parameters",
+			args:     []string{"err", `"%s %s"`, "errAccessingNode", "n[0]"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mutators.HandleWithMessagef(tt.args)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	handlers := mutators.Handlers(mutators.Options{
+		Matchers: map[string][]mutators.MatcherFn{
+			"Wrap": {func(args []string) []string {
+				if args[0] != `"Failed to connect"` {
+					return nil
+				}
+				return []string{args[0], `"retryable"`, "true"}
+			}},
+			"Errorf": {func(args []string) []string {
+				if len(args) != 2 || args[0] != `"Unknown app %s"` {
+					return nil
+				}
+				return []string{`"Unknown app"`, `"app"`, args[1]}
+			}},
+		},
+		FieldNames: map[string]string{"ns": "namespace"},
+	})
+
+	assert.Equal(t, `errkit.Wrap(err, "Failed to connect", "retryable", true)`,
+		handlers["Wrap"]([]string{"err", `"Failed to connect"`}))
+	assert.Equal(t, `errkit.Wrap(err, "Failed to create PVC in ns", "namespace", ns)`,
+		handlers["Wrapf"]([]string{"err", `"Failed to create PVC in ns %s"`, "ns"}))
+	assert.Equal(t, `errkit.Wrap(err, "Failed to list pods", "namespace", ns)`,
+		handlers["Errorf"]([]string{`"Failed to list pods, ns: %s: %w"`, "ns", "err"}))
+	assert.Equal(t, `errkit.New("Unknown app", "app", name)`,
+		handlers["Errorf"]([]string{`"Unknown app %s"`, "name"}))
+	assert.Equal(t, `errkit.New(fmt.Sprintf("Unknown pod %s", name))`,
+		handlers["Errorf"]([]string{`"Unknown pod %s"`, "name"}))
+	assert.Equal(t, `errkit.Wrap(err, "Failed to create PVC in ns", "ns", ns)`,
+		mutators.DefaultHandlers["Wrapf"]([]string{"err", `"Failed to create PVC in ns %s"`, "ns"}))
+}
+
+func TestHandlersQualifier(t *testing.T) {
+	opts := mutators.Options{Qualifier: "xerrkit"}
+	handlers := mutators.Handlers(opts)
+
+	assert.Equal(t, `xerrkit.Wrap(err, "Failed to create PVC")`, handlers["Wrap"]([]string{"err", `"Failed to create PVC"`}))
+	assert.Equal(t, `xerrkit.New(fmt.Sprintf("%x", id))`, handlers["Errorf"]([]string{`"%x"`, "id"}))
+	assert.Equal(t, `xerrkit.WithStack(err)`, handlers["WithStack"]([]string{"err"}))
+	assert.Equal(t, `xerrkit.NewSentinelErr("not found")`, mutators.Sentinels(opts)["New"]([]string{`"not found"`}))
+}
@@ -0,0 +1,210 @@
+package mutators
+
+import (
+	"strings"
+)
+
+// Gap describes the separator of the invocation arguments: the one after the opening parenthesis,
+// the ones between the arguments, or the one before the closing parenthesis.
+type Gap struct {
+	// Comments within the separator in the order of appearance, e.g. `// the cause`
+	Comments []string
+	// Break is the last line break within the separator followed by the indentation, empty if there is none
+	Break string
+}
+
+func (g Gap) empty() bool {
+	return len(g.Comments) == 0 && g.Break == ""
+}
+
+// NewGap returns the gap holding the comments and the last line break of the separator text.
+func NewGap(text string, comments ...string) Gap {
+	gap := Gap{Comments: comments}
+	if idx := strings.LastIndexByte(text, '\n'); idx != -1 {
+		gap.Break = "\n" + indentation(text[idx+1:])
+	}
+
+	return gap
+}
+
+// indentation returns the leading whitespace of the text.
+func indentation(text string) string {
+	return text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+}
+
+// CallGaps splits the invocation, e.g. `errors.Wrap(err,\n\t"message")`, into the trimmed arguments
+// and the gaps around them: the result has one gap more than arguments.
+// Comments are not recognized, the gaps only keep the line breaks.
+func CallGaps(call string) ([]string, []Gap, error) {
+	_, rest, _ := strings.Cut(call, ".")
+	_, argsStr, err := splitFunctionCall(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pieces, err := splitRawArguments(argsStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The piece after the trailing comma, if any, only holds the separator before the closing parenthesis
+	trailing := ""
+	if len(pieces) > 1 && strings.TrimSpace(pieces[len(pieces)-1]) == "" {
+		trailing = pieces[len(pieces)-1]
+		pieces = pieces[:len(pieces)-1]
+	}
+	if len(pieces) == 1 && strings.TrimSpace(pieces[0]) == "" {
+		return nil, []Gap{NewGap(pieces[0])}, nil
+	}
+
+	args := make([]string, 0, len(pieces))
+	gaps := make([]Gap, 0, len(pieces)+1)
+	before := ""
+	for _, piece := range pieces {
+		arg := strings.TrimSpace(piece)
+		start := strings.Index(piece, arg)
+		gaps = append(gaps, NewGap(before+piece[:start]))
+		args = append(args, arg)
+		before = piece[start+len(arg):]
+	}
+
+	return args, append(gaps, NewGap(before+trailing)), nil
+}
+
+// Relayout returns the invocation rewritten by a handler with the gaps of the original one re-emitted,
+// so the line breaks and comments between the original arguments are kept.
+// The original arguments are passed as the handler received them, so the ones the handler keeps
+// are recognized in the rewritten invocation. The gap preceding an original argument goes right after the argument
+// the previous one went to, so the keys added by the handler stay next to their values, e.g. the gap before
+// the format value goes before its key, not between the key and the value.
+// If the original invocation has neither line breaks nor comments, the rewritten one is returned as is.
+func Relayout(rewritten string, args []string, gaps []Gap) string {
+	if len(gaps) != len(args)+1 || allEmpty(gaps) {
+		return rewritten
+	}
+
+	open := strings.IndexByte(rewritten, '(')
+	if open == -1 {
+		return rewritten
+	}
+	_, rewrittenArgs, err := parseFunctionCall(rewritten)
+	if err != nil {
+		return rewritten
+	}
+
+	n := len(rewrittenArgs)
+	result := make([]Gap, n+1)
+	result[0] = gaps[0]
+	if len(args) > 0 {
+		result[n] = mergeGaps(result[n], gaps[len(args)])
+	}
+
+	// Find the rewritten argument each original one went to
+	prev := -1
+	for i := 0; i < len(args); i++ {
+		if i > 0 {
+			if target := prev + 1; 0 < target && target < n {
+				result[target] = mergeGaps(result[target], gaps[i])
+			} else {
+				result[n] = mergeGaps(result[n], gaps[i])
+			}
+		}
+
+		next := min(prev+1, max(n-1, 0))
+		for j := prev + 1; j < n; j++ {
+			if rewrittenArgs[j] == args[i] {
+				next = j
+				break
+			}
+		}
+		prev = next
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(rewritten[:open+1])
+	for k := 0; k <= n; k++ {
+		gap := result[k]
+		comments := gap.Comments
+		if 0 < k && k < n {
+			// Block comments stick to the preceding argument, in front of the comma, as gofmt places them
+			for len(comments) > 0 && !strings.HasPrefix(comments[0], "//") {
+				sb.WriteString(" " + comments[0])
+				comments = comments[1:]
+			}
+			sb.WriteString(",")
+		}
+		if k == n && n > 0 && (gap.Break != "" || hasLineComment(gap)) {
+			// The closing parenthesis on its own line requires the trailing comma
+			sb.WriteString(",")
+		}
+
+		broken := false
+		for _, comment := range comments {
+			if !broken && k > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(comment)
+			broken = false
+			if strings.HasPrefix(comment, "//") {
+				sb.WriteString(lineBreak(gap))
+				broken = true
+			}
+		}
+		if !broken && gap.Break != "" {
+			sb.WriteString(gap.Break)
+			broken = true
+		}
+
+		if k < n {
+			if !broken && k > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(rewrittenArgs[k])
+		}
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+func allEmpty(gaps []Gap) bool {
+	for _, gap := range gaps {
+		if !gap.empty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeGaps returns the gap holding comments of both, keeping the first line break.
+func mergeGaps(a, b Gap) Gap {
+	result := Gap{Comments: append(append([]string{}, a.Comments...), b.Comments...), Break: a.Break}
+	if result.Break == "" {
+		result.Break = b.Break
+	}
+	if len(result.Comments) == 0 {
+		result.Comments = nil
+	}
+
+	return result
+}
+
+func hasLineComment(gap Gap) bool {
+	for _, comment := range gap.Comments {
+		if strings.HasPrefix(comment, "//") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lineBreak returns the line break of the gap, or a bare one if the gap has none.
+func lineBreak(gap Gap) string {
+	if gap.Break != "" {
+		return gap.Break
+	}
+
+	return "\n"
+}
@@ -0,0 +1,66 @@
+package mutators_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/migrator/matcher_v2/mutators"
+)
+
+func TestRelayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  string
+		rewritten string
+		expected  string
+	}{
+		{
+			name:      "Single line is kept as is",
+			original:  `errors.Wrapf(err, "Failed to get PVC %s", pvcName)`,
+			rewritten: `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+			expected:  `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+		},
+		{
+			name:      "Line breaks go before the keys of the values",
+			original:  "errors.Wrapf(err,\n\t\t\"Failed to get PVC %s\",\n\t\tpvcName)",
+			rewritten: `errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`,
+			expected:  "errkit.Wrap(err,\n\t\t\"Failed to get PVC\",\n\t\t\"PVC\", pvcName)",
+		},
+		{
+			name:      "Line breaks after the opening parenthesis and before the closing one",
+			original:  "errors.Wrap(\n\terrors.New(\"message\"),\n\t\"wrapped\",\n)",
+			rewritten: `errkit.Wrap(errkit.New("message"), "wrapped")`,
+			expected:  "errkit.Wrap(\n\terrkit.New(\"message\"),\n\t\"wrapped\",\n)",
+		},
+		{
+			name:      "Transformed format string takes the line break",
+			original:  "errors.Errorf(\n\t\"Failed to get PVC %s\", pvcName)",
+			rewritten: `errkit.New("Failed to get PVC", "PVC", pvcName)`,
+			expected:  "errkit.New(\n\t\"Failed to get PVC\", \"PVC\", pvcName)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			args, gaps, err := mutators.CallGaps(tt.original)
+			assert.NoError(t, err)
+			assert.Equal(t, len(args)+1, len(gaps))
+			assert.Equal(t, tt.expected, mutators.Relayout(tt.rewritten, args, gaps))
+		})
+	}
+}
+
+func TestRelayoutComments(t *testing.T) {
+	args := []string{"err", `"Failed to get PVC %s"`, "pvcName"}
+	gaps := []mutators.Gap{
+		{},
+		mutators.NewGap(" \n\t\t", "// the cause"),
+		mutators.NewGap(" ", "/* the claim */"),
+		mutators.NewGap("\n\t", "// trailing"),
+	}
+
+	rewritten := mutators.Relayout(`errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)`, args, gaps)
+	assert.Equal(t, "errkit.Wrap(err, // the cause\n\t\t\"Failed to get PVC\" /* the claim */, \"PVC\", pvcName, // trailing\n\t)", rewritten)
+}
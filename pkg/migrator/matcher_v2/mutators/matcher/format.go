@@ -0,0 +1,123 @@
+package param_matcher
+
+import (
+	"strconv"
+	"strings"
+)
+
+// verb is a formatting verb found in the template, e.g. %s, %5.2f or %[2]d
+type verb struct {
+	start int // index of the '%'
+	end   int // index after the verb character
+	char  byte
+	// spec holds the flags, width and precision of the verb, e.g. "+" for %+v or "5.2" for %5.2f
+	spec string
+	// index is the explicit one-based index of the argument, 0 if not given
+	index int
+}
+
+// plain reports whether the verb renders the argument the same way a structured field does.
+func (v verb) plain() bool {
+	return v.spec == "" && strings.IndexByte("vsdt", v.char) != -1
+}
+
+// String returns the verb without the explicit argument index, e.g. %[2]5.2f => %5.2f
+func (v verb) String() string {
+	return "%" + v.spec + string(v.char)
+}
+
+// scanVerbs returns the formatting verbs of the template, each of them consuming a single argument.
+// Escaped percent signs are skipped. It returns false if some verb consumes extra arguments for the width
+// or precision, or has a malformed argument index, as arguments can't be mapped to verbs one to one then.
+func scanVerbs(template string) ([]verb, bool) {
+	var verbs []verb
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+
+		v := verb{start: i}
+		spec := strings.Builder{}
+		j := i + 1
+		for ; j < len(template); j++ {
+			c := template[j]
+			switch {
+			case strings.IndexByte("+-# 0123456789.", c) != -1:
+				spec.WriteByte(c)
+				continue
+			case c == '[':
+				closing := strings.IndexByte(template[j:], ']')
+				if closing == -1 || v.index != 0 {
+					return nil, false
+				}
+				index, err := strconv.Atoi(template[j+1 : j+closing])
+				if err != nil || index < 1 {
+					return nil, false
+				}
+				v.index = index
+				j += closing
+				continue
+			case c == '*':
+				return nil, false
+			}
+			break
+		}
+		if j == len(template) {
+			break
+		}
+
+		if template[j] != '%' {
+			v.end, v.char, v.spec = j+1, template[j], spec.String()
+			verbs = append(verbs, v)
+		}
+		i = j
+	}
+
+	return verbs, true
+}
+
+// normalizeIndexes takes the template followed by its arguments and turns explicit argument indexes
+// into the sequential order of verbs, reordering the arguments accordingly.
+// E.g. "%[2]s in %[1]s", ns, name => "%s in %s", name, ns
+// It returns false if some argument is not formatted exactly once.
+func normalizeIndexes(input []string) ([]string, bool) {
+	if len(input) == 0 {
+		return input, true
+	}
+
+	verbs, ok := scanVerbs(input[0])
+	if !ok || len(verbs) != len(input)-1 {
+		return nil, false
+	}
+
+	indexed := false
+	for _, v := range verbs {
+		indexed = indexed || v.index != 0
+	}
+	if !indexed {
+		return input, true
+	}
+
+	template := strings.Builder{}
+	args := make([]string, 0, len(verbs))
+	used := make([]bool, len(verbs))
+	next, pos := 0, 0
+	for _, v := range verbs {
+		if v.index != 0 {
+			next = v.index - 1
+		}
+		if next >= len(verbs) || used[next] {
+			return nil, false
+		}
+		used[next] = true
+		args = append(args, input[1+next])
+		next++
+
+		template.WriteString(input[0][pos:v.start])
+		template.WriteString(v.String())
+		pos = v.end
+	}
+	template.WriteString(input[0][pos:])
+
+	return append([]string{template.String()}, args...), true
+}
@@ -7,6 +7,17 @@ import (
 	"mig/pkg/migrator/matcher_v2/helpers"
 )
 
+// Matches a single variable appended to the string with the + operator
+// Pattern breakdown:
+// ^"(.+?)\s*:\s*"\s*\+\s*(\w+)$
+// ^"        : Start with a double quote
+// (.+?)     : Non-greedy match to capture the static part of the string before ":" (message)
+// \s*:\s*   : Match the colon ":" with optional spaces around it
+// "\s*\+    : Match the + operator with optional spaces
+// (\w+)     : Match the variable name (alphanumeric word)
+// $         : End of the string (ensures that no additional variables or content follows)
+var singleVariableAppendRegex = regexp.MustCompile(`^"(.+?)\s*:\s*"\s*\+\s*(\w+)$`)
+
 // MatchSingleVariableAppend matches templates where a single variable is appended to the message
 // using the + operator and transforms them into the correct format where the variable is passed separately.
 // E.g. "failed to read env from dir:" + dirName => "failed to read env from dir", "dir", dirName
@@ -23,19 +34,7 @@ func MatchSingleVariableAppend(input []string) []string {
 		return nil
 	}
 
-	// Define a regex to match a single variable appended to the string with the + operator
-	// Pattern breakdown:
-	// ^"(.+?)\s*:\s*"\s*\+\s*(\w+)$
-	// ^"        : Start with a double quote
-	// (.+?)     : Non-greedy match to capture the static part of the string before ":" (message)
-	// \s*:\s*   : Match the colon ":" with optional spaces around it
-	// "\s*\+    : Match the + operator with optional spaces
-	// (\w+)     : Match the variable name (alphanumeric word)
-	// $         : End of the string (ensures that no additional variables or content follows)
-	pattern := `^"(.+?)\s*:\s*"\s*\+\s*(\w+)$`
-
-	re := regexp.MustCompile(pattern)
-	match := re.FindStringSubmatch(str)
+	match := singleVariableAppendRegex.FindStringSubmatch(str)
 
 	// If no match is found, return nil
 	if match == nil {
@@ -0,0 +1,194 @@
+package param_matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mig/pkg/migrator/matcher_v2/helpers"
+)
+
+var (
+	// Matches the key labelling the placeholder, e.g. "namespace: " or "namespace="
+	labelRegex = regexp.MustCompile(`(\w+)\s*[:=]\s*$`)
+	// Matches the key followed by whitespace only, e.g. "namespace "
+	keyRegex = regexp.MustCompile(`(\w+)\s+$`)
+	// Matches the text separating grouped placeholders, e.g. "%s/%s" or "%s:%s"
+	groupSeparatorRegex = regexp.MustCompile(`^\s*[/:]\s*$`)
+	// Matches the valid key
+	keyNameRegex = regexp.MustCompile(`^\w+$`)
+)
+
+// template is the tokenized template: the text segments surrounding the placeholders and their arguments.
+// texts[i] is the text preceding the i-th placeholder, the last text follows the last placeholder.
+type template struct {
+	texts []string
+	// original keeps the texts before any of them is consumed
+	original []string
+	args     []string
+}
+
+// MatchTemplate takes the template followed by its arguments and turns every placeholder into
+// a key/value pair, leaving the rest of the template as the message.
+// Any number of the following segments is supported:
+//   - `key: %s` and `key=%s` labelled placeholders, the key is removed from the message;
+//   - `key %s` placeholders in a comma separated list, e.g. "{namespace %s, name %s}", the key is removed as well;
+//   - placeholders grouped by `/` or `:`, e.g. "PVC %s/%s", the key is inferred from the argument;
+//   - bare placeholders, e.g. "failed to create PVC %s", the key is inferred from the words preceding it,
+//     which are kept in the message.
+//
+// Keys are inferred through helpers.InferVariableName. Any verb but %w is supported, explicit argument indexes
+// are resolved. Values of the verbs rendering the argument differently from a structured field, e.g. %x, %T or %5.2f,
+// are wrapped into fmt.Sprintf, so the rendered output stays the same. Escaped percent signs are unescaped.
+// If the template can't be tokenized or the keys can't be inferred, it returns nil.
+// E.g. "Failed to update Deployment{Namespace: %s, Name: %s}", ns, name => "Failed to update Deployment", "namespace", ns, "name", name
+func MatchTemplate(input []string) []string {
+	if len(input) < 2 {
+		return nil
+	}
+
+	input, ok := normalizeIndexes(input)
+	if !ok {
+		return nil
+	}
+
+	quoted := input[0]
+	if len(quoted) < 2 || !strings.HasPrefix(quoted, `"`) || !strings.HasSuffix(quoted, `"`) {
+		return nil
+	}
+	body := quoted[1 : len(quoted)-1]
+
+	verbs, _ := scanVerbs(body)
+	t := &template{args: input[1:]}
+	pos := 0
+	for _, v := range verbs {
+		if v.char == 'w' {
+			return nil
+		}
+		t.texts = append(t.texts, body[pos:v.start])
+		pos = v.end
+	}
+	t.texts = append(t.texts, body[pos:])
+	t.original = append([]string(nil), t.texts...)
+
+	keys := make([]string, 0, len(verbs))
+	for i := 0; i < len(verbs); {
+		last := t.group(i)
+		for j := i; j <= last; j++ {
+			key := t.key(j, last > i)
+			if key == "" {
+				return nil
+			}
+			keys = append(keys, key)
+		}
+		i = last + 1
+	}
+
+	message := t.message()
+	if message == "" {
+		return nil
+	}
+
+	result := []string{`"` + message + `"`}
+	seen := map[string]struct{}{}
+	for i, key := range keys {
+		if _, ok := seen[key]; ok || !keyNameRegex.MatchString(key) {
+			return nil
+		}
+		seen[key] = struct{}{}
+
+		value := t.args[i]
+		if !verbs[i].plain() {
+			value = fmt.Sprintf("fmt.Sprintf(%q, %s)", verbs[i].String(), value)
+		}
+		result = append(result, `"`+key+`"`, value)
+	}
+
+	return result
+}
+
+// group returns the index of the last placeholder grouped with the i-th one by `/` or `:` separators.
+// The separators are consumed.
+func (t *template) group(i int) int {
+	last := i
+	for last+1 < len(t.args) && groupSeparatorRegex.MatchString(t.texts[last+1]) {
+		last++
+	}
+	if last == i {
+		return i
+	}
+
+	t.texts[i] = strings.TrimRight(t.texts[i], " :")
+	for j := i + 1; j <= last; j++ {
+		t.texts[j] = ""
+	}
+
+	return last
+}
+
+// key returns the key of the i-th placeholder, consuming the text labelling it.
+// It returns empty string if the key can't be inferred.
+func (t *template) key(i int, grouped bool) string {
+	arg := t.args[i]
+	if grouped {
+		return helpers.InferVariableName(nil, arg)
+	}
+
+	text := t.texts[i]
+	if match := labelRegex.FindStringSubmatchIndex(text); match != nil {
+		t.consumeField(i, match[0])
+		return helpers.InferVariableName([]string{text[match[2]:match[3]]}, arg)
+	}
+
+	if match := keyRegex.FindStringSubmatchIndex(text); match != nil && t.listed(i, match[0]) {
+		t.consumeField(i, match[0])
+		return helpers.InferVariableName([]string{text[match[2]:match[3]]}, arg)
+	}
+
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "":
+		return helpers.InferVariableName(nil, arg)
+	case trimmed == text:
+		// The placeholder is glued to the preceding word, e.g. "namespace%s"
+		return ""
+	default:
+		return helpers.InferVariableName(helpers.GetLastWords(trimmed), arg)
+	}
+}
+
+// listed reports whether the i-th placeholder, which key starts at the offset of its text,
+// is a member of the comma separated list.
+func (t *template) listed(i, keyStart int) bool {
+	if len(t.args) < 2 {
+		return false
+	}
+
+	before := strings.TrimRight(t.original[i][:keyStart], " ")
+	after := strings.TrimLeft(t.original[i+1], " ")
+
+	return strings.HasSuffix(before, ",") || strings.HasSuffix(before, "{") ||
+		strings.HasPrefix(after, ",") || strings.HasPrefix(after, "}")
+}
+
+// consumeField removes the key of the i-th placeholder starting at the offset of its text,
+// along with the list punctuation surrounding the field.
+func (t *template) consumeField(i, keyStart int) {
+	before := strings.TrimRight(t.texts[i][:keyStart], " ")
+	t.texts[i] = strings.TrimSuffix(strings.TrimSuffix(before, ","), "{")
+
+	after := strings.TrimLeft(t.texts[i+1], " ")
+	t.texts[i+1] = strings.TrimPrefix(strings.TrimPrefix(after, ","), "}")
+}
+
+// message joins the remaining texts of the template.
+func (t *template) message() string {
+	var parts []string
+	for _, text := range t.texts {
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return strings.ReplaceAll(strings.Join(parts, " "), "%%", "%")
+}
@@ -0,0 +1,830 @@
+package param_matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	parammatcher "mig/pkg/migrator/matcher_v2/mutators/matcher"
+)
+
+func TestMatchOneVariableSimple(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Single variable without object field",
+			input: []string{
+				`"Unable to parse sizeFormat %s"`,
+				`sizeFmt`,
+			},
+			expected: []string{
+				`"Unable to parse sizeFormat"`,
+				`"sizeFormat"`,
+				`sizeFmt`,
+			},
+		},
+		{
+			name: "Single variable with different placeholder type",
+			input: []string{
+				`"Unable to create PVC %v"`,
+				`pvc`,
+			},
+			expected: []string{
+				`"Unable to create PVC"`,
+				`"PVC"`,
+				`pvc`,
+			},
+		},
+		{
+			name: "Single variable with object field",
+			input: []string{
+				`"Failed to create job %s"`,
+				`job.name`,
+			},
+			expected: []string{
+				`"Failed to create job"`,
+				`"job"`,
+				`job.name`,
+			},
+		},
+		{
+			name: "Single variable with acronym",
+			input: []string{
+				`"Failed to get PV %s"`,
+				`pvName`,
+			},
+			expected: []string{
+				`"Failed to get PV"`,
+				`"PV"`,
+				`pvName`,
+			},
+		},
+		{
+			name: "Single variable with contextual name",
+			input: []string{
+				`"Unable to create PV for volume %v"`,
+				`pv`,
+			},
+			expected: []string{
+				`"Unable to create PV for volume"`,
+				`"volume"`,
+				`pv`,
+			},
+		},
+		{
+			name: "Corner case with multiple placeholders",
+			input: []string{
+				`"%s %s"`,
+				`errAccessingNode`,
+				`n[0]`,
+			},
+			expected: nil, // Expecting no match
+		},
+		{
+			name: "Parameter is in the middle of text, name should be taken as usually from word before placeholder",
+			input: []string{
+				`"Error waiting for application %s to be ready to reset it"`,
+				"c.name",
+			},
+			expected: []string{
+				`"Error waiting for application to be ready to reset it"`,
+				`"application"`,
+				`c.name`,
+			},
+		},
+		{
+			name:  "Parameter name blacklist, should not be taken as variable name",
+			input: []string{`"Failed to uninstall %s helm release"`, "cb.chart.Release"},
+			expected: []string{
+				`"Failed to uninstall helm release"`,
+				`"release"`,
+				`cb.chart.Release`,
+			},
+		},
+		{
+			name:  "Parameter name constructed of two last words, should be taken as variable name",
+			input: []string{`"Error getting the pod and container name %s."`, "esi.name"},
+			expected: []string{
+				`"Error getting the pod and container name ."`,
+				`"containerName"`,
+				`esi.name`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := parammatcher.MatchTemplate(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMatchTwoVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Valid input with two variables without colons",
+			input: []string{
+				`"Failed to create pod. Failed to override pod specs. Namespace %s, NameFmt %s"`,
+				`opts.Namespace`,
+				`opts.GenerateName`,
+			},
+			expected: []string{
+				`"Failed to create pod. Failed to override pod specs."`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"nameFmt"`,
+				`opts.GenerateName`,
+			},
+		},
+		{
+			name: "Valid input with two variables with colons",
+			input: []string{
+				`"Failed to create pod. Failed to override pod specs. Namespace: %s, NameFmt: %s"`,
+				`opts.Namespace`,
+				`opts.GenerateName`,
+			},
+			expected: []string{
+				`"Failed to create pod. Failed to override pod specs."`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"nameFmt"`,
+				`opts.GenerateName`,
+			},
+		},
+		{
+			name: "Valid input with mixed colon usage",
+			input: []string{
+				`"Error encountered. Namespace: %s, ServiceName %s"`,
+				`opts.Namespace`,
+				`opts.ServiceName`,
+			},
+			expected: []string{
+				`"Error encountered."`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"serviceName"`,
+				`opts.ServiceName`,
+			},
+		},
+		{
+			name: "Invalid template with one variable",
+			input: []string{
+				`"Error encountered. Namespace: %s"`,
+				`opts.Namespace`,
+				`opts.ServiceName`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid template with no variables",
+			input: []string{
+				`"Error encountered."`,
+				`opts.Namespace`,
+				`opts.ServiceName`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Extra parameters",
+			input: []string{
+				`"Error encountered. Namespace: %s, ServiceName: %s"`,
+				`opts.Namespace`,
+				`opts.ServiceName`,
+				`extraParam`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Missing parameters",
+			input: []string{
+				`"Error encountered. Namespace: %s, ServiceName: %s"`,
+				`opts.Namespace`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Different placeholder types",
+			input: []string{
+				`"Error encountered. Namespace: %s, Replicas: %d"`,
+				`opts.Namespace`,
+				`opts.Replicas`,
+			},
+			expected: []string{
+				`"Error encountered."`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"replicas"`,
+				`opts.Replicas`,
+			},
+		},
+		{
+			name: "Whitespace variations without colons",
+			input: []string{
+				`"Error encountered. Namespace%s, ServiceName %s"`,
+				`opts.Namespace`,
+				`opts.ServiceName`,
+			},
+			expected: nil, // Because missing space before %s in first variable
+		},
+		{
+			name: "Valid input with different variable names",
+			input: []string{
+				`"Deployment failed. Region: %s, ClusterName: %s"`,
+				`opts.Region`,
+				`opts.ClusterName`,
+			},
+			expected: []string{
+				`"Deployment failed."`,
+				`"region"`,
+				`opts.Region`,
+				`"clusterName"`,
+				`opts.ClusterName`,
+			},
+		},
+		{
+			name: "Valid input with additional spaces and colons",
+			input: []string{
+				`"Operation failed. Zone:   %s, InstanceName:    %s"`,
+				`opts.Zone`,
+				`opts.InstanceName`,
+			},
+			expected: []string{
+				`"Operation failed."`,
+				`"zone"`,
+				`opts.Zone`,
+				`"instanceName"`,
+				`opts.InstanceName`,
+			},
+		},
+		{
+			name: "Valid input with one colon missing",
+			input: []string{
+				`"Operation failed. Zone %s, InstanceName: %s"`,
+				`opts.Zone`,
+				`opts.InstanceName`,
+			},
+			expected: []string{
+				`"Operation failed."`,
+				`"zone"`,
+				`opts.Zone`,
+				`"instanceName"`,
+				`opts.InstanceName`,
+			},
+		},
+		{
+			name: "Valid input with both colons missing",
+			input: []string{
+				`"Operation failed. Zone %s, InstanceName %s"`,
+				`opts.Zone`,
+				`opts.InstanceName`,
+			},
+			expected: []string{
+				`"Operation failed."`,
+				`"zone"`,
+				`opts.Zone`,
+				`"instanceName"`,
+				`opts.InstanceName`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := parammatcher.MatchTemplate(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMatchTwoVariablesNoName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Valid input with two variables without colons",
+			input: []string{
+				`"Failed to validate if PVC %s:%s exists"`,
+				`namespace`,
+				`claimName`,
+			},
+			expected: []string{
+				`"Failed to validate if PVC exists"`,
+				`"namespace"`,
+				`namespace`,
+				`"claimName"`,
+				`claimName`,
+			},
+		},
+		{
+			name: "Valid input with two variables with colons",
+			input: []string{
+				`"Failed to validate if PVC %s:%s exists"`,
+				`opts.Namespace`,
+				`opts.ClaimName`,
+			},
+			expected: []string{
+				`"Failed to validate if PVC exists"`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"claimName"`,
+				`opts.ClaimName`,
+			},
+		},
+		{
+			name: "Valid input with different separators",
+			input: []string{
+				`"Failed to find VolumeSnapshot: %s/%s"`,
+				`opts.Namespace`,
+				`opts.GenerateName`,
+			},
+			expected: []string{
+				`"Failed to find VolumeSnapshot"`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"generateName"`,
+				`opts.GenerateName`,
+			},
+		},
+		{
+			name: "Valid input with mixed separators",
+			input: []string{
+				`"Operation failed. PVC %s/%s not found"`,
+				`opts.Namespace`,
+				`opts.PVCName`,
+			},
+			expected: []string{
+				`"Operation failed. PVC not found"`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"PVCName"`,
+				`opts.PVCName`,
+			},
+		},
+		{
+			name: "Invalid template with one placeholder",
+			input: []string{
+				`"Failed to validate if PVC %s exists"`,
+				`namespace`,
+				`claimName`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid template with no placeholders",
+			input: []string{
+				`"Failed to validate if PVC exists"`,
+				`namespace`,
+				`claimName`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Extra parameters",
+			input: []string{
+				`"Failed to validate if PVC %s:%s exists"`,
+				`namespace`,
+				`claimName`,
+				`extraParam`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Missing parameters",
+			input: []string{
+				`"Failed to validate if PVC %s:%s exists"`,
+				`namespace`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Different placeholder types",
+			input: []string{
+				`"Failed to validate if PVC %d:%s exists"`,
+				`namespace`,
+				`claimName`,
+			},
+			expected: []string{
+				`"Failed to validate if PVC exists"`,
+				`"namespace"`,
+				`namespace`,
+				`"claimName"`,
+				`claimName`,
+			},
+		},
+		{
+			name: "Whitespace variations without separators",
+			input: []string{
+				`"Failed to validate if PVC%s%s exists"`,
+				`namespace`,
+				`claimName`,
+			},
+			expected: nil, // Because missing separators between %s
+		},
+		{
+			name: "Valid input with additional spaces and different separators",
+			input: []string{
+				`"Failed to validate if PVC  %s : %s exists"`,
+				`opts.Namespace`,
+				`opts.ClaimName`,
+			},
+			expected: []string{
+				`"Failed to validate if PVC exists"`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"claimName"`,
+				`opts.ClaimName`,
+			},
+		},
+		{
+			name: "Valid input with object fields and mixed cases",
+			input: []string{
+				`"Failed to validate if PVC %s:%s exists"`,
+				`opts.Namespace`,
+				`opts.ClaimName`,
+			},
+			expected: []string{
+				`"Failed to validate if PVC exists"`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"claimName"`,
+				`opts.ClaimName`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := parammatcher.MatchTemplate(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMatchCurlyBracedTwoVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Valid input with two variables without colons",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"name"`,
+				"name",
+			},
+		},
+		{
+			name: "Valid input with two variables with colons",
+			input: []string{
+				`"Could not update Deployment{Namespace: %s, Name: %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"name"`,
+				"name",
+			},
+		},
+		{
+			name: "Valid input with mixed colon usage",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name: %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"name"`,
+				"name",
+			},
+		},
+		{
+			name: "Invalid template with one variable",
+			input: []string{
+				`"Could not update Deployment{Namespace %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid template with no variables",
+			input: []string{
+				`"Could not update Deployment"`,
+				"namespace",
+				"name",
+			},
+			expected: nil,
+		},
+		{
+			name: "Extra parameters",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name %s}"`,
+				"namespace",
+				"name",
+				"extra",
+			},
+			expected: nil,
+		},
+		{
+			name: "Missing parameters",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name %s}"`,
+				"namespace",
+			},
+			expected: nil,
+		},
+		{
+			name: "Different placeholder types",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Replicas %d}"`,
+				"namespace",
+				"replicas",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"replicas"`,
+				"replicas",
+			},
+		},
+		{
+			name: "Whitespace variations without colons",
+			input: []string{
+				`"Could not update Deployment{Namespace%s,Name %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: nil, // Because missing space before %s in first variable
+		},
+		{
+			name: "Valid input with different variable names",
+			input: []string{
+				`"Error updating Service{Region %s, ServiceName %s}"`,
+				"region",
+				"serviceName",
+			},
+			expected: []string{
+				`"Error updating Service"`,
+				`"region"`,
+				"region",
+				`"serviceName"`,
+				"serviceName",
+			},
+		},
+		{
+			name: "Valid input with additional spaces and colons",
+			input: []string{
+				`"Failed to modify Pod{Cluster: %s, PodName: %s}"`,
+				"cluster",
+				"podName",
+			},
+			expected: []string{
+				`"Failed to modify Pod"`,
+				`"cluster"`,
+				"cluster",
+				`"podName"`,
+				"podName",
+			},
+		},
+		{
+			name: "Valid input with one colon missing",
+			input: []string{
+				`"Could not update Deployment{Namespace: %s, Name %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"name"`,
+				"name",
+			},
+		},
+		{
+			name: "Valid input with both colons missing",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name %s}"`,
+				"namespace",
+				"name",
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				"namespace",
+				`"name"`,
+				"name",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := parammatcher.MatchTemplate(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Three labelled variables",
+			input: []string{
+				`"Failed to create snapshot. Namespace: %s, Name: %s, Class=%s"`,
+				`opts.Namespace`,
+				`opts.Name`,
+				`opts.ClassName`,
+			},
+			expected: []string{
+				`"Failed to create snapshot."`,
+				`"namespace"`,
+				`opts.Namespace`,
+				`"name"`,
+				`opts.Name`,
+				`"class"`,
+				`opts.ClassName`,
+			},
+		},
+		{
+			name: "Labelled and grouped variables",
+			input: []string{
+				`"Failed to copy PVC %s/%s to pod: %s"`,
+				`namespace`,
+				`pvcName`,
+				`pod.Name`,
+			},
+			expected: []string{
+				`"Failed to copy PVC to"`,
+				`"namespace"`,
+				`namespace`,
+				`"pvcName"`,
+				`pvcName`,
+				`"pod"`,
+				`pod.Name`,
+			},
+		},
+		{
+			name: "Four curly braced variables",
+			input: []string{
+				`"Could not update Deployment{Namespace %s, Name %s, Replicas: %v, Image=%s}"`,
+				`namespace`,
+				`name`,
+				`replicas`,
+				`image`,
+			},
+			expected: []string{
+				`"Could not update Deployment"`,
+				`"namespace"`,
+				`namespace`,
+				`"name"`,
+				`name`,
+				`"replicas"`,
+				`replicas`,
+				`"image"`,
+				`image`,
+			},
+		},
+		{
+			name: "Bare variables with inferred keys",
+			input: []string{
+				`"Failed to restore backup %s into namespace %s"`,
+				`backupID`,
+				`ns`,
+			},
+			expected: []string{
+				`"Failed to restore backup into namespace"`,
+				`"backup"`,
+				`backupID`,
+				`"namespace"`,
+				`ns`,
+			},
+		},
+		{
+			name: "Duplicate keys",
+			input: []string{
+				`"Failed to get pod: %s, pod=%s"`,
+				`a`,
+				`b`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Verbs rendered differently are wrapped into Sprintf",
+			input: []string{
+				`"Unexpected response. Type: %T, Checksum: %x, Ratio: %5.2f, Spec: %+v, Status: %q"`,
+				`resp`,
+				`sum`,
+				`ratio`,
+				`spec`,
+				`status`,
+			},
+			expected: []string{
+				`"Unexpected response."`,
+				`"type"`,
+				`fmt.Sprintf("%T", resp)`,
+				`"checksum"`,
+				`fmt.Sprintf("%x", sum)`,
+				`"ratio"`,
+				`fmt.Sprintf("%5.2f", ratio)`,
+				`"spec"`,
+				`fmt.Sprintf("%+v", spec)`,
+				`"status"`,
+				`fmt.Sprintf("%q", status)`,
+			},
+		},
+		{
+			name: "Explicit argument indexes",
+			input: []string{
+				`"Failed to scale. Replicas: %[2]d, Deployment: %[1]s"`,
+				`name`,
+				`replicas`,
+			},
+			expected: []string{
+				`"Failed to scale."`,
+				`"replicas"`,
+				`replicas`,
+				`"deployment"`,
+				`name`,
+			},
+		},
+		{
+			name: "Escaped percent sign",
+			input: []string{
+				`"Usage is over 90%% on volume %s"`,
+				`volume.Name`,
+			},
+			expected: []string{
+				`"Usage is over 90% on volume"`,
+				`"volume"`,
+				`volume.Name`,
+			},
+		},
+		{
+			name: "Argument formatted twice",
+			input: []string{
+				`"Failed to get %[1]s, retrying %[1]s"`,
+				`name`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Wrapped error",
+			input: []string{
+				`"Failed to get pod %s: %w"`,
+				`name`,
+				`err`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Only variables",
+			input: []string{
+				`"Namespace: %s"`,
+				`namespace`,
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result := parammatcher.MatchTemplate(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
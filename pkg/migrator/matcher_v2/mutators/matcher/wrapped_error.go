@@ -0,0 +1,87 @@
+package param_matcher
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches the %w verb along with flags and explicit argument indexes, e.g. %[2]w or %*w
+var wrapVerbRegex = regexp.MustCompile(`%[-+# 0-9.*\[\]]*w`)
+
+// HasWrapVerb reports whether the template contains the %w verb.
+func HasWrapVerb(template string) bool {
+	verbs, ok := scanVerbs(template)
+	if !ok {
+		// Can't tell which argument is wrapped, but it's still there
+		return wrapVerbRegex.MatchString(template)
+	}
+
+	for _, v := range verbs {
+		if v.char == 'w' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchWrappedError takes the template followed by its arguments and extracts the error wrapped by
+// the single %w verb of the template. It returns the wrapped error and the input with both the verb and
+// its argument removed. The separator preceding the verb is removed too.
+// Explicit argument indexes are resolved, e.g. "%[2]s: %[1]w", err, name => err; "%s", name.
+// If the template has no %w verb, has several of them, or arguments can't be matched to the verbs,
+// it returns empty string and nil.
+// E.g. "failed to sync %s: %w", name, err => err; "failed to sync %s", name
+func MatchWrappedError(input []string) (string, []string) {
+	if len(input) < 2 {
+		return "", nil
+	}
+
+	input, ok := normalizeIndexes(input)
+	if !ok {
+		return "", nil
+	}
+
+	template := input[0]
+	if len(template) < 2 || !strings.HasPrefix(template, `"`) || !strings.HasSuffix(template, `"`) {
+		return "", nil
+	}
+
+	verbs, _ := scanVerbs(template)
+	wrapIdx := -1
+	for i, v := range verbs {
+		if v.char != 'w' {
+			continue
+		}
+		if wrapIdx != -1 {
+			// Wrapping multiple errors is not supported
+			return "", nil
+		}
+		wrapIdx = i
+	}
+	if wrapIdx == -1 {
+		return "", nil
+	}
+
+	// Drop the verb along with the separator preceding it, e.g. "failed: %w" => "failed"
+	before := strings.TrimRight(template[:verbs[wrapIdx].start], " :;,-")
+	after := strings.TrimLeft(template[verbs[wrapIdx].end:], " ")
+	if before == `"` {
+		// The verb starts the template, e.g. "%w: failed" => "failed"
+		after = strings.TrimLeft(after, " :;,-")
+	}
+
+	message := before + after
+	if before != `"` && after != `"` {
+		message = before + " " + after
+	}
+
+	rest := []string{message}
+	for i, arg := range input[1:] {
+		if i != wrapIdx {
+			rest = append(rest, arg)
+		}
+	}
+
+	return input[1+wrapIdx], rest
+}
@@ -0,0 +1,90 @@
+package param_matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	parammatcher "mig/pkg/migrator/matcher_v2/mutators/matcher"
+)
+
+func TestMatchWrappedError(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           []string
+		expectedWrapped string
+		expected        []string
+	}{
+		{
+			name:            "Wrapped error at the end",
+			input:           []string{`"failed to sync %s: %w"`, `name`, `err`},
+			expectedWrapped: `err`,
+			expected:        []string{`"failed to sync %s"`, `name`},
+		},
+		{
+			name:            "Wrapped error at the start",
+			input:           []string{`"%w: failed to sync %s"`, `err`, `name`},
+			expectedWrapped: `err`,
+			expected:        []string{`"failed to sync %s"`, `name`},
+		},
+		{
+			name:            "Wrapped error in the middle",
+			input:           []string{`"sync %s failed %w for %d times"`, `name`, `err`, `count`},
+			expectedWrapped: `err`,
+			expected:        []string{`"sync %s failed for %d times"`, `name`, `count`},
+		},
+		{
+			name:            "Escaped percent sign and flags",
+			input:           []string{`"100%% of %5.2f failed: %w"`, `size`, `errors.Cause(err)`},
+			expectedWrapped: `errors.Cause(err)`,
+			expected:        []string{`"100%% of %5.2f failed"`, `size`},
+		},
+		{
+			name:            "Explicit argument indexes",
+			input:           []string{`"%[2]s: %[1]w"`, `err`, `name`},
+			expectedWrapped: `err`,
+			expected:        []string{`"%s"`, `name`},
+		},
+		{
+			name:            "Only the wrapped error",
+			input:           []string{`"%w"`, `err`},
+			expectedWrapped: `err`,
+			expected:        []string{`""`},
+		},
+		{
+			name:     "No match - no wrapped error",
+			input:    []string{`"failed to sync %s"`, `name`},
+			expected: nil,
+		},
+		{
+			name:     "No match - multiple wrapped errors",
+			input:    []string{`"%w: %w"`, `err1`, `err2`},
+			expected: nil,
+		},
+		{
+			name:     "No match - argument formatted twice",
+			input:    []string{`"%[1]s: %[1]w"`, `err`},
+			expected: nil,
+		},
+		{
+			name:     "No match - arguments don't match verbs",
+			input:    []string{`"failed %s: %w"`, `err`},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped, result := parammatcher.MatchWrappedError(tt.input)
+			assert.Equal(t, tt.expectedWrapped, wrapped)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHasWrapVerb(t *testing.T) {
+	assert.True(t, parammatcher.HasWrapVerb(`"failed: %w"`))
+	assert.True(t, parammatcher.HasWrapVerb(`"failed: %[1]w"`))
+	assert.False(t, parammatcher.HasWrapVerb(`"100%%w"`))
+	assert.False(t, parammatcher.HasWrapVerb(`"failed: %v"`))
+}
@@ -42,16 +42,46 @@ func Mutator(errorsPart string, handlerMap HandlerMap) string {
 	return errorsPart
 }
 
+// SplitCall splits the invocation of the package function, e.g. `errors.Wrap(err, "message")`,
+// into the package name, the function name and the arguments.
+func SplitCall(call string) (pkg string, funcName string, args []string, err error) {
+	pkg, rest, found := strings.Cut(call, ".")
+	if !found {
+		return "", "", nil, fmt.Errorf("no package selector found in function call")
+	}
+
+	funcName, args, err = parseFunctionCall(rest)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return strings.TrimSpace(pkg), funcName, args, nil
+}
+
 // parseFunctionCall parses a function call string to extract the function name and arguments.
 // For example, given "Wrap(err, \"message\")", it returns "Wrap" and ["err", "\"message\""].
 func parseFunctionCall(callStr string) (funcName string, args []string, err error) {
+	funcName, argsStr, err := splitFunctionCall(callStr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args, err = splitArguments(argsStr)
+	if err != nil {
+		return "", nil, err
+	}
+	return funcName, args, nil
+}
+
+// splitFunctionCall splits a function call string into the function name and the arguments string
+// enclosed in the parentheses. For example, given "Wrap(err, \"message\")", it returns "Wrap" and "err, \"message\"".
+func splitFunctionCall(callStr string) (funcName string, argsStr string, err error) {
 	// Find the function name by locating the first '('
 	idx := strings.Index(callStr, "(")
 	if idx == -1 {
-		return "", nil, fmt.Errorf("no opening parenthesis found in function call")
+		return "", "", fmt.Errorf("no opening parenthesis found in function call")
 	}
 	funcName = strings.TrimSpace(callStr[:idx])
-	argsStr := callStr[idx+1:]
 
 	// Find the matching closing parenthesis
 	depth := 1
@@ -82,20 +112,33 @@ func parseFunctionCall(callStr string) (funcName string, args []string, err erro
 		}
 	}
 	if depth != 0 {
-		return "", nil, fmt.Errorf("unbalanced parentheses in function call")
+		return "", "", fmt.Errorf("unbalanced parentheses in function call")
 	}
 
 	// Extract arguments string
-	argsStr = callStr[idx+1 : endIdx]
-	args, err = splitArguments(argsStr)
-	if err != nil {
-		return "", nil, err
-	}
-	return funcName, args, nil
+	return funcName, callStr[idx+1 : endIdx], nil
 }
 
 // splitArguments splits the arguments string into individual arguments, handling nested parentheses and string literals.
 func splitArguments(argsStr string) ([]string, error) {
+	pieces, err := splitRawArguments(argsStr)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{}
+	for i, piece := range pieces {
+		arg := strings.TrimSpace(piece)
+		if arg != "" || i < len(pieces)-1 {
+			args = append(args, arg)
+		}
+	}
+	return args, nil
+}
+
+// splitRawArguments splits the arguments string at the top-level commas, keeping the whitespace around the arguments.
+// A trailing comma results in the last piece holding only the whitespace before the closing parenthesis.
+func splitRawArguments(argsStr string) ([]string, error) {
 	args := []string{}
 	start := 0
 	depth := 0
@@ -119,8 +162,7 @@ func splitArguments(argsStr string) ([]string, error) {
 				inString = true
 				stringChar = rune(c)
 			} else if c == ',' && depth == 0 {
-				arg := strings.TrimSpace(argsStr[start:i])
-				args = append(args, arg)
+				args = append(args, argsStr[start:i])
 				start = i + 1
 			}
 		}
@@ -128,11 +170,7 @@ func splitArguments(argsStr string) ([]string, error) {
 	if inString {
 		return nil, fmt.Errorf("unclosed string literal in arguments")
 	}
-	arg := strings.TrimSpace(argsStr[start:])
-	if arg != "" {
-		args = append(args, arg)
-	}
-	return args, nil
+	return append(args, argsStr[start:]), nil
 }
 
 // Handler functions with signatures only
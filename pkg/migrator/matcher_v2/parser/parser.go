@@ -33,6 +33,23 @@ func ParseLine(line string) (prefix string, errorsPart string, suffix string, er
 	return prefix, errorsPart, suffix, nil
 }
 
+// ParseLineAt splits the line into prefix, errorsPart, and suffix, where errorsPart is the invocation
+// of the function from pkg package which starts at the start index.
+// Returns an error if any unexpected parsing error occurs.
+// If the invocation is not found, returns the entire line as prefix, and errorsPart and suffix as empty strings.
+func ParseLineAt(line string, start int, pkg string) (prefix string, errorsPart string, suffix string, err error) {
+	end, err := FindInvocationEnd(line, start, pkg)
+	if err != nil {
+		return "", "", "", err
+	}
+	if end == -1 {
+		// No invocation found, return the line as prefix
+		return line, "", "", nil
+	}
+
+	return line[:start], line[start:end], line[end:], nil
+}
+
 // FindErrorsInvocation locates the 'errors' function call in the line.
 // Returns the start and end indices of the invocation and an error if parsing fails.
 func FindErrorsInvocation(line string) (start int, end int, err error) {
@@ -42,8 +59,24 @@ func FindErrorsInvocation(line string) (start int, end int, err error) {
 		return -1, -1, nil // 'errors.' not found
 	}
 
-	// Move past 'errors.' to get to the function name
-	funcNameStart := idx + len("errors.")
+	end, err = FindInvocationEnd(line, idx, "errors")
+	if err != nil || end == -1 {
+		return -1, -1, err
+	}
+
+	return idx, end, nil
+}
+
+// FindInvocationEnd locates the end of the pkg function call starting at the start index of the line.
+// Returns the end index of the invocation (exclusive) or -1 if there is no invocation at the start index,
+// and an error if parsing fails.
+func FindInvocationEnd(line string, start int, pkg string) (end int, err error) {
+	if start < 0 || start > len(line) || !strings.HasPrefix(line[start:], pkg+".") {
+		return -1, nil // 'pkg.' not found
+	}
+
+	// Move past 'pkg.' to get to the function name
+	funcNameStart := start + len(pkg) + 1
 	funcNameEnd := funcNameStart
 
 	// Find the end of the function name
@@ -55,7 +88,7 @@ func FindErrorsInvocation(line string) (start int, end int, err error) {
 	remainingLine := line[funcNameEnd:]
 	parenIdx := strings.Index(remainingLine, "(")
 	if parenIdx == -1 {
-		return -1, -1, nil // No '(' after function name
+		return -1, nil // No '(' after function name
 	}
 	parenIdx += funcNameEnd // Adjust index to absolute
 
@@ -70,7 +103,7 @@ func FindErrorsInvocation(line string) (start int, end int, err error) {
 			depth--
 			if depth == 0 {
 				// Found matching ')'
-				return idx, i + 1, nil // end index is exclusive
+				return i + 1, nil // end index is exclusive
 			}
 		} else if c == '"' || c == '\'' || c == '`' {
 			// Skip over string literals
@@ -90,16 +123,16 @@ func FindErrorsInvocation(line string) (start int, end int, err error) {
 				i++
 			}
 			if i >= len(line) {
-				return -1, -1, fmt.Errorf("unclosed string literal starting at position %d", i)
+				return -1, fmt.Errorf("unclosed string literal starting at position %d", i)
 			}
 		}
 		i++
 	}
 
 	if depth != 0 {
-		return -1, -1, fmt.Errorf("unmatched parentheses in line")
+		return -1, fmt.Errorf("unmatched parentheses in line")
 	}
 
 	// No matching ')' found
-	return -1, -1, fmt.Errorf("could not find matching closing parenthesis")
+	return -1, fmt.Errorf("could not find matching closing parenthesis")
 }
@@ -110,3 +110,64 @@ func TestParseLine(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLineAt(t *testing.T) {
+	tests := []struct {
+		input          string
+		start          int
+		pkg            string
+		wantPrefix     string
+		wantErrorsPart string
+		wantSuffix     string
+		expectError    bool
+	}{
+		// Aliased package
+		{
+			input:          `return pkgerrors.Wrap(err, "Failed to get secrets")`,
+			start:          7,
+			pkg:            "pkgerrors",
+			wantPrefix:     `return `,
+			wantErrorsPart: `pkgerrors.Wrap(err, "Failed to get secrets")`,
+			wantSuffix:     ``,
+		},
+		// Second invocation in the line
+		{
+			input:          `if errors.Is(err, ErrNotFound) { return pkgerrors.New("Not found") }`,
+			start:          40,
+			pkg:            "pkgerrors",
+			wantPrefix:     `if errors.Is(err, ErrNotFound) { return `,
+			wantErrorsPart: `pkgerrors.New("Not found")`,
+			wantSuffix:     ` }`,
+		},
+		// No invocation at the index
+		{
+			input:          `if errors.Is(err, ErrNotFound) {`,
+			start:          3,
+			pkg:            "pkgerrors",
+			wantPrefix:     `if errors.Is(err, ErrNotFound) {`,
+			wantErrorsPart: ``,
+			wantSuffix:     ``,
+		},
+		// Invocation continued on the next line
+		{
+			input:       `return errors.Wrapf(err,`,
+			start:       7,
+			pkg:         "errors",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			prefix, errorsPart, suffix, err := parser.ParseLineAt(tt.input, tt.start, tt.pkg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ParseLineAt(%q) error = %v, expectError = %v", tt.input, err, tt.expectError)
+				return
+			}
+			if prefix != tt.wantPrefix || errorsPart != tt.wantErrorsPart || suffix != tt.wantSuffix {
+				t.Errorf("ParseLineAt(%q) =\n  prefix      %q\n  errorsPart  %q\n  suffix      %q\nwant:\n  prefix      %q\n  errorsPart  %q\n  suffix      %q",
+					tt.input, prefix, errorsPart, suffix, tt.wantPrefix, tt.wantErrorsPart, tt.wantSuffix)
+			}
+		})
+	}
+}
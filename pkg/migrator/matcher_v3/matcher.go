@@ -0,0 +1,339 @@
+package matcher_v3
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mig/pkg/internal/edit"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/matcher_v2/mutators"
+	"mig/pkg/migrator/resolver"
+)
+
+// rewriter holds the state of a single file migration
+type rewriter struct {
+	tokFile          *token.File
+	src              []byte
+	calls            []*ast.CallExpr          // sorted by position
+	comments         []*ast.Comment           // sorted by position
+	literals         []*ast.BasicLit          // string literals spanning multiple lines
+	sentinels        map[*ast.CallExpr]string // invocations declaring sentinel errors, mapped to the variable names
+	results          map[int]common.Call      // results of the handled invocations by their offset
+	handlers         mutators.HandlerMap
+	sentinelHandlers mutators.HandlerMap
+}
+
+// Matcher migrates files according to the configuration.
+type Matcher struct {
+	cfg      common.Config
+	pkgs     common.Packages
+	handlers mutators.HandlerMap
+	// sentinelHandlers are used for the invocations declaring sentinel errors
+	sentinelHandlers mutators.HandlerMap
+}
+
+var defaultMatcher = New(common.DefaultConfig)
+
+// New returns the Matcher using the configuration.
+func New(cfg common.Config) *Matcher {
+	opts := mutators.NewOptions(cfg)
+
+	return &Matcher{
+		cfg:              cfg,
+		pkgs:             cfg.Packages.WithDefaults(),
+		handlers:         mutators.Handlers(opts),
+		sentinelHandlers: mutators.Sentinels(opts),
+	}
+}
+
+// HandleFile receives the source of a Go file and returns the transformed source
+// using the default configuration.
+func HandleFile(src []byte) (common.FileResult, error) {
+	return defaultMatcher.HandleFile(src)
+}
+
+// HandleFile receives the source of a Go file and returns the transformed source.
+// Every invocation of the source package, `github.com/pkg/errors` by default, is either replaced with
+// the target package invocation or marked as to be migrated manually. Calls in comments and string literals
+// are never touched, calls spanning multiple lines are handled as a whole keeping the line breaks and comments
+// between the arguments.
+// If the file doesn't import the source package, the result is empty.
+func (m *Matcher) HandleFile(src []byte) (common.FileResult, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return common.FileResult{}, err
+	}
+
+	spec, name := resolver.FindImport(file, m.pkgs.Source)
+	if spec == nil {
+		// No 'errors' import found, nothing to do
+		return common.FileResult{}, nil
+	}
+
+	r := &rewriter{
+		tokFile:          fset.File(file.Pos()),
+		src:              src,
+		calls:            resolver.FindCalls(file, name),
+		literals:         multiLineLiterals(file, fset),
+		sentinels:        resolver.FindSentinels(file, name),
+		results:          map[int]common.Call{},
+		handlers:         m.handlers,
+		sentinelHandlers: m.sentinelHandlers,
+	}
+	for _, group := range file.Comments {
+		r.comments = append(r.comments, group.List...)
+	}
+
+	importEdit := edit.Edit{
+		Start: r.offset(spec.Pos()),
+		End:   r.offset(spec.End()),
+		Text:  strconv.Quote(m.pkgs.Target),
+	}
+	if alias := m.pkgs.Alias(); alias != "" {
+		importEdit.Text = alias + " " + importEdit.Text
+	}
+	edits := []edit.Edit{importEdit}
+	r.results[importEdit.Start] = r.call(spec, importEdit.Text, common.Migrated, "import", "")
+
+	manualLines := map[int]struct{}{}
+	for _, call := range r.topLevelCalls(0, len(src)) {
+		text, manual := r.rewrite(call)
+		edits = append(edits, edit.Edit{Start: r.offset(call.Pos()), End: r.offset(call.End()), Text: text})
+		if manual && m.cfg.TodoComment != "" {
+			manualLines[r.tokFile.Line(call.End())] = struct{}{}
+		}
+	}
+
+	todoOffsets := map[int]struct{}{}
+	for line := range manualLines {
+		todoOffsets[r.todoOffset(line, edits)] = struct{}{}
+	}
+	for offset := range todoOffsets {
+		edits = append(edits, edit.Edit{Start: offset, End: offset, Text: m.cfg.MarkTodo("")})
+	}
+
+	content, err := formatSource(src, edit.Apply(src, edits))
+	if err != nil {
+		return common.FileResult{}, err
+	}
+
+	return common.FileResult{Content: content, Calls: r.sortedResults()}, nil
+}
+
+// formatSource returns the migrated source formatted with gofmt if the original one is gofmt-clean,
+// otherwise only checks it's still valid Go, so the code untouched by the migration keeps its layout.
+func formatSource(original, migrated []byte) ([]byte, error) {
+	if formatted, err := format.Source(original); err == nil && bytes.Equal(formatted, original) {
+		return format.Source(migrated)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", migrated, parser.ParseComments); err != nil {
+		return nil, err
+	}
+
+	return migrated, nil
+}
+
+// sortedResults returns the results in the order of appearance.
+func (r *rewriter) sortedResults() []common.Call {
+	offsets := make([]int, 0, len(r.results))
+	for offset := range r.results {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+
+	results := make([]common.Call, 0, len(offsets))
+	for _, offset := range offsets {
+		results = append(results, r.results[offset])
+	}
+
+	return results
+}
+
+// call returns the result of the node handling.
+func (r *rewriter) call(node ast.Node, rewritten string, status common.Status, matcher, reason string) common.Call {
+	pos := r.tokFile.Position(node.Pos())
+
+	return common.Call{
+		Line:      pos.Line,
+		Column:    pos.Column,
+		Original:  string(r.src[r.offset(node.Pos()):r.offset(node.End())]),
+		Rewritten: rewritten,
+		Status:    status,
+		Matcher:   matcher,
+		Reason:    reason,
+	}
+}
+
+// topLevelCalls returns calls located within src[start:end] which are not nested into other found calls.
+func (r *rewriter) topLevelCalls(start, end int) []*ast.CallExpr {
+	var result []*ast.CallExpr
+	pos := start
+	for _, call := range r.calls {
+		callStart, callEnd := r.offset(call.Pos()), r.offset(call.End())
+		if callStart >= pos && callEnd <= end {
+			result = append(result, call)
+			pos = callEnd
+		}
+	}
+
+	return result
+}
+
+// render returns src[start:end] with all nested calls rewritten.
+// The second return value reports whether any of the nested calls has to be migrated manually.
+func (r *rewriter) render(start, end int) (string, bool) {
+	sb := strings.Builder{}
+	manual := false
+	pos := start
+	for _, call := range r.topLevelCalls(start, end) {
+		text, m := r.rewrite(call)
+		manual = manual || m
+		sb.Write(r.src[pos:r.offset(call.Pos())])
+		sb.WriteString(text)
+		pos = r.offset(call.End())
+	}
+	sb.Write(r.src[pos:end])
+
+	return sb.String(), manual
+}
+
+// rewrite returns the replacement for the call and records the result of the call handling.
+// The second return value reports whether the call (or any of the nested calls) has to be migrated manually.
+func (r *rewriter) rewrite(call *ast.CallExpr) (string, bool) {
+	sel := call.Fun.(*ast.SelectorExpr)
+	funcName := sel.Sel.Name
+
+	args := make([]string, 0, len(call.Args))
+	argsManual := false
+	for _, arg := range call.Args {
+		text, m := r.render(r.offset(arg.Pos()), r.offset(arg.End()))
+		argsManual = argsManual || m
+		args = append(args, text)
+	}
+
+	text, status, matcher, reason := "", common.NeedsManual, "Handle"+funcName, ""
+	handler, exists := r.handlers[funcName]
+	sentinel := r.sentinels[call]
+	if sentinelHandler, ok := r.sentinelHandlers[funcName]; ok && sentinel != "" {
+		handler, matcher = sentinelHandler, matcher+"Sentinel"
+	}
+
+	switch {
+	case !exists:
+		matcher = ""
+		reason = fmt.Sprintf("errors.%s is not supported", funcName)
+	case call.Ellipsis.IsValid():
+		// Variadic invocations can't be split into separate parameters
+		reason = fmt.Sprintf("variadic arguments of errors.%s can't be migrated automatically", funcName)
+	default:
+		reason = fmt.Sprintf("arguments of errors.%s can't be migrated automatically", funcName)
+		if transformed := handler(args); transformed != "" {
+			text, status, reason = mutators.Relayout(transformed, args, r.gaps(call)), common.Migrated, ""
+		}
+	}
+
+	if status == common.NeedsManual {
+		// Keep the invocation as is, but still rewrite the nested calls
+		rendered, _ := r.render(r.offset(sel.End()), r.offset(call.End()))
+		text = string(r.src[r.offset(call.Pos()):r.offset(sel.End())]) + rendered
+	}
+
+	result := r.call(call, text, status, matcher, reason)
+	result.Func, result.Sentinel = funcName, sentinel
+	r.results[r.offset(call.Pos())] = result
+
+	return text, argsManual || status == common.NeedsManual
+}
+
+// gaps returns the separators of the call arguments with the comments within them.
+func (r *rewriter) gaps(call *ast.CallExpr) []mutators.Gap {
+	bounds := []int{r.offset(call.Lparen) + 1}
+	for _, arg := range call.Args {
+		bounds = append(bounds, r.offset(arg.Pos()), r.offset(arg.End()))
+	}
+	bounds = append(bounds, r.offset(call.Rparen))
+
+	gaps := make([]mutators.Gap, 0, len(call.Args)+1)
+	for i := 0; i < len(bounds); i += 2 {
+		start, end := bounds[i], bounds[i+1]
+		text := strings.Builder{}
+		var comments []string
+		for _, c := range r.comments {
+			if cStart, cEnd := r.offset(c.Pos()), r.offset(c.End()); start <= cStart && cEnd <= end {
+				text.Write(r.src[start:cStart])
+				comments = append(comments, c.Text)
+				start = cEnd
+			}
+		}
+		text.Write(r.src[start:end])
+		gaps = append(gaps, mutators.NewGap(text.String(), comments...))
+	}
+
+	return gaps
+}
+
+// todoOffset returns the offset the TODO comment should be inserted at to mark the line.
+// If the end of the line falls into one of the edits or into a string literal spanning multiple lines,
+// the comment is moved to the last line of that edit or literal, so it never changes the string value.
+func (r *rewriter) todoOffset(line int, edits []edit.Edit) int {
+	offset := lineEnd(r.src, r.tokFile, line)
+	for moved := true; moved; {
+		moved = false
+		for _, e := range edits {
+			if e.Start < offset && offset < e.End {
+				offset = lineEnd(r.src, r.tokFile, r.tokFile.Line(r.tokFile.Pos(e.End)))
+				moved = true
+			}
+		}
+		for _, lit := range r.literals {
+			if r.offset(lit.Pos()) < offset && offset < r.offset(lit.End()) {
+				offset = lineEnd(r.src, r.tokFile, r.tokFile.Line(lit.End()))
+				moved = true
+			}
+		}
+	}
+
+	return offset
+}
+
+// multiLineLiterals returns the string literals of the file spanning multiple lines.
+func multiLineLiterals(file *ast.File, fset *token.FileSet) []*ast.BasicLit {
+	var literals []*ast.BasicLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if ok && lit.Kind == token.STRING && fset.Position(lit.Pos()).Line != fset.Position(lit.End()).Line {
+			literals = append(literals, lit)
+		}
+
+		return true
+	})
+
+	return literals
+}
+
+func (r *rewriter) offset(pos token.Pos) int {
+	return r.tokFile.Offset(pos)
+}
+
+// lineEnd returns the offset of the line ending of the given line.
+func lineEnd(src []byte, tokFile *token.File, line int) int {
+	offset := len(src)
+	if line < tokFile.LineCount() {
+		offset = tokFile.Offset(tokFile.LineStart(line+1)) - 1
+	}
+
+	for offset > 0 && (src[offset-1] == '\r' || src[offset-1] == '\n') {
+		offset--
+	}
+
+	return offset
+}
@@ -0,0 +1,468 @@
+package matcher_v3_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	common "mig/pkg/migrator/common"
+	matcher "mig/pkg/migrator/matcher_v3"
+)
+
+func TestHandleFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Wrap and import are migrated",
+			input: `package foo
+
+import (
+	"github.com/pkg/errors"
+)
+
+func foo() error {
+	return errors.Wrap(err, "Failed to get controller namespace")
+}
+`,
+			expected: `package foo
+
+import (
+	"github.com/kanisterio/errkit"
+)
+
+func foo() error {
+	return errkit.Wrap(err, "Failed to get controller namespace")
+}
+`,
+		},
+		{
+			name: "Multi-line Wrapf",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrapf(err,
+		"Failed to get PVC %s",
+		pvcName)
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.Wrap(err,
+		"Failed to get PVC",
+		"PVC", pvcName)
+}
+`,
+		},
+		{
+			name: "Comments between arguments are kept",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrapf(err, // the cause
+		"Failed to get PVC %s" /* the claim */, pvcName,
+	)
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.Wrap(err, // the cause
+		"Failed to get PVC" /* the claim */, "PVC", pvcName,
+	)
+}
+`,
+		},
+		{
+			name: "Comments between values stay in front of their keys",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrapf(err, "Failed to get PVC %s in %s",
+		pvcName, /* the claim */
+		namespace)
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.Wrap(err, "Failed to get PVC in",
+		"PVC", pvcName, /* the claim */
+		"in", namespace)
+}
+`,
+		},
+		{
+			name: "Files which aren't gofmt-clean aren't reformatted",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo()  error {
+	x :=  1
+	return errors.Wrapf(err, "Failed to get PVC %s", pvcName)
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo()  error {
+	x :=  1
+	return errkit.Wrap(err, "Failed to get PVC", "PVC", pvcName)
+}
+`,
+		},
+		{
+			name: "Calls in comments and string literals are not touched",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+// errors.Wrap(err, "comment")
+func foo() error {
+	fmt.Println("errors.Wrap(err, \"string\")")
+	return errors.New("message")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+// errors.Wrap(err, "comment")
+func foo() error {
+	fmt.Println("errors.Wrap(err, \"string\")")
+	return errkit.New("message")
+}
+`,
+		},
+		{
+			name: "WithStack and WithMessage are migrated",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	if err := c.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithMessage(err, "Failed to close")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	if err := c.Close(); err != nil {
+		return errkit.WithStack(err)
+	}
+	return errkit.Wrap(err, "Failed to close")
+}
+`,
+		},
+		{
+			name: "Sentinels are migrated to the sentinel constructor",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+var (
+	ErrNotFound = errors.New("Not found")
+	ErrExists   = errors.Errorf("Exists")
+)
+
+func foo() error {
+	errLocal := errors.New("Local")
+	return errLocal
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+var (
+	ErrNotFound = errkit.NewSentinelErr("Not found")
+	ErrExists   = errkit.New("Exists")
+)
+
+func foo() error {
+	errLocal := errkit.New("Local")
+	return errLocal
+}
+`,
+		},
+		{
+			name: "Unknown function is marked as to be migrated manually",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Cause(err), nil
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errors.Cause(err), nil // TODO: migrate manually
+}
+`,
+		},
+		{
+			name: "TODO comment is not put into the multi-line string literal",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() {
+	fmt.Println(errors.Cause(err), ` + "`a" + `
+b` + "`" + `)
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() {
+	fmt.Println(errors.Cause(err), ` + "`a" + `
+b` + "`" + `) // TODO: migrate manually
+}
+`,
+		},
+		{
+			name: "Nested calls are migrated",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrap(errors.New("inner"), "outer")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.Wrap(errkit.New("inner"), "outer")
+}
+`,
+		},
+		{
+			name: "Aliased import",
+			input: `package foo
+
+import pkgerrors "github.com/pkg/errors"
+
+func foo() error {
+	return pkgerrors.New("message")
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.New("message")
+}
+`,
+		},
+		{
+			name: "Local variable named errors is not touched",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo(errors Collector) error {
+	errors.Wrap(err, "message")
+	return nil
+}
+`,
+			expected: `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo(errors Collector) error {
+	errors.Wrap(err, "message")
+	return nil
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := matcher.HandleFile([]byte(tt.input))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result.Content))
+		})
+	}
+}
+
+func TestHandleFileWithoutErrorsImport(t *testing.T) {
+	input := `package foo
+
+import "errors"
+
+func foo() error {
+	return errors.New("message")
+}
+`
+	result, err := matcher.HandleFile([]byte(input))
+	assert.NoError(t, err)
+	assert.Nil(t, result.Content)
+	assert.Equal(t, common.Unchanged, result.Status())
+}
+
+func TestHandleFileResults(t *testing.T) {
+	input := `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	if err := bar(); err != nil {
+		return errors.Cause(errors.New("inner"))
+	}
+	return errors.Wrapf(err, "%s %s", errAccessingNode, n[0])
+}
+`
+	result, err := matcher.HandleFile([]byte(input))
+	assert.NoError(t, err)
+	assert.Equal(t, common.NeedsManual, result.Status())
+	assert.Equal(t, []common.Call{
+		{
+			Line:      3,
+			Column:    8,
+			Original:  `"github.com/pkg/errors"`,
+			Rewritten: `"github.com/kanisterio/errkit"`,
+			Status:    common.Migrated,
+			Matcher:   "import",
+		},
+		{
+			Line:      7,
+			Column:    10,
+			Func:      "Cause",
+			Original:  `errors.Cause(errors.New("inner"))`,
+			Rewritten: `errors.Cause(errkit.New("inner"))`,
+			Status:    common.NeedsManual,
+			Reason:    "errors.Cause is not supported",
+		},
+		{
+			Line:      7,
+			Column:    23,
+			Func:      "New",
+			Original:  `errors.New("inner")`,
+			Rewritten: `errkit.New("inner")`,
+			Status:    common.Migrated,
+			Matcher:   "HandleNew",
+		},
+		{
+			Line:      9,
+			Column:    9,
+			Func:      "Wrapf",
+			Original:  `errors.Wrapf(err, "%s %s", errAccessingNode, n[0])`,
+			Rewritten: `errors.Wrapf(err, "%s %s", errAccessingNode, n[0])`,
+			Status:    common.NeedsManual,
+			Matcher:   "HandleWrapf",
+			Reason:    "arguments of errors.Wrapf can't be migrated automatically",
+		},
+	}, result.Calls)
+}
+
+func TestHandleFileWithoutTodoComment(t *testing.T) {
+	src := `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrapf(err, "%s %s", errAccessingNode, n[0])
+}
+`
+
+	result, err := matcher.New(common.Config{}).HandleFile([]byte(src))
+	assert.NoError(t, err)
+	assert.Equal(t, `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errors.Wrapf(err, "%s %s", errAccessingNode, n[0])
+}
+`, string(result.Content))
+	assert.Equal(t, common.NeedsManual, result.Status())
+}
+
+func TestHandleFileWithExtraMatchers(t *testing.T) {
+	src := `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrapf(err, "Failed to install app=%s", name)
+}
+`
+
+	cfg := common.DefaultConfig
+	cfg.Matchers = map[string][]func([]string) []string{
+		"Wrapf": {func(args []string) []string {
+			return []string{`"Failed to install"`, `"application"`, args[1]}
+		}},
+	}
+	result, err := matcher.New(cfg).HandleFile([]byte(src))
+	assert.NoError(t, err)
+	assert.Equal(t, `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo() error {
+	return errkit.Wrap(err, "Failed to install", "application", name)
+}
+`, string(result.Content))
+}
+
+func TestHandleFileWithCustomPackages(t *testing.T) {
+	src := `package foo
+
+import "example.com/errors"
+
+var ErrNotFound = errors.New("Not found")
+
+func foo() error {
+	return errors.WithStack(ErrNotFound)
+}
+`
+
+	cfg := common.DefaultConfig
+	cfg.Packages = common.Packages{Source: "example.com/errors", Target: "example.com/errkit", Qualifier: "xerrkit"}
+	result, err := matcher.New(cfg).HandleFile([]byte(src))
+	assert.NoError(t, err)
+	assert.Equal(t, `package foo
+
+import xerrkit "example.com/errkit"
+
+var ErrNotFound = xerrkit.NewSentinelErr("Not found")
+
+func foo() error {
+	return xerrkit.WithStack(ErrNotFound)
+}
+`, string(result.Content))
+}
@@ -3,37 +3,85 @@ package migrator
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	common "mig/pkg/migrator/common"
 	"mig/pkg/migrator/matcher_v1"
 	"mig/pkg/migrator/matcher_v2"
+	"mig/pkg/migrator/matcher_v3"
+	"mig/pkg/migrator/registry"
 )
 
-type HandleLine func(string) string
-type MigrationHandlers []HandleLine
+// HandleLine receives a line of a file and returns the result of its transformation.
+// The result is Unchanged if the handler doesn't apply to the line.
+type HandleLine func(common.Line) common.Result
+
+// HandleFile receives the whole content of a file and returns the result of its transformation.
+type HandleFile func([]byte) (common.FileResult, error)
+
+// MigrationHandlers is a set of handlers used to migrate a file.
+// If File handler is set, it receives the whole file, otherwise Lines handlers are applied to every line,
+// the first handler returning a result other than Unchanged wins.
+type MigrationHandlers struct {
+	Lines []HandleLine
+	File  HandleFile
+	// Packages are the packages migrated between, the defaults are used for the empty fields
+	Packages common.Packages
+}
 
 type MigratorVersion string
 
 const V1 MigratorVersion = "v1"
 const V2 MigratorVersion = "v2"
+const V3 MigratorVersion = "v3"
 
-func GetMigratorHandlers(version MigratorVersion) (MigrationHandlers, error) {
+// GetMigratorHandlers returns handlers of the migrator version configured with cfg.
+// V1 only supports the default packages and doesn't support the rewrite rules.
+func GetMigratorHandlers(version MigratorVersion, cfg common.Config) (MigrationHandlers, error) {
+	pkgs := cfg.Packages.WithDefaults()
 	switch version {
 	case V1:
-		return MigrationHandlers{
+		if pkgs != common.DefaultPackages {
+			return MigrationHandlers{}, errors.New(fmt.Sprintf("migrator: version %v supports migration from %s to %s only",
+				version, common.DefaultPackages.Source, common.DefaultPackages.Target))
+		}
+		if len(cfg.Matchers) > 0 {
+			return MigrationHandlers{}, errors.New(fmt.Sprintf("migrator: version %v doesn't support rewrite rules", version))
+		}
+		return MigrationHandlers{Lines: []HandleLine{
 			common.MatchImport,
-			matcher_v1.MatchErrorfWithNamedParams,
-			matcher_v1.MatchWrapfWithNamedParams,
-			matcher_v1.MatchWrapfStderr,
-			matcher_v1.MatchSimpleWraps,
-			matcher_v1.MatchSimpleErrorsNew,
-		}, nil
+			registryHandler(matcher_v1.Registry),
+		}}, nil
 	case V2:
-		return MigrationHandlers{
-			common.MatchImport,
-			matcher_v2.HandleLine,
-		}, nil
+		return MigrationHandlers{Lines: []HandleLine{
+			common.ImportMatcher(pkgs),
+			matcher_v2.New(cfg).HandleSourceLine,
+		}, Packages: pkgs}, nil
+	case V3:
+		return MigrationHandlers{File: matcher_v3.New(cfg).HandleFile, Packages: pkgs}, nil
 	default:
-		return nil, errors.New(fmt.Sprintf("migrator: unknown version %v", version))
+		return MigrationHandlers{}, errors.New(fmt.Sprintf("migrator: unknown version %v", version))
+	}
+}
+
+// registryHandler adapts the registry of matchers, which receive the text of the line only, reporting the name
+// of the matcher rewriting the line. Lines marked with `// TODO: Fixme` by the matcher need manual migration.
+// Only the lines with invocations resolved to the source package imported as `errors` are passed to the matchers,
+// so the invocations of the standard library `errors` package are never rewritten.
+func registryHandler(r *registry.Registry) HandleLine {
+	return func(line common.Line) common.Result {
+		if line.File == nil || line.File.Name != "errors" || len(line.File.CallsIn(line.Number, line.Text)) == 0 {
+			return common.Result{Text: line.Text}
+		}
+
+		name, text := r.Match(line.Text)
+		switch {
+		case text == "":
+			return common.Result{Text: line.Text}
+		case strings.HasSuffix(text, "// TODO: Fixme"):
+			return common.Result{Text: text, Status: common.NeedsManual, Matcher: name, Reason: "fmt.Sprintf can't be migrated automatically"}
+		default:
+			return common.Result{Text: text, Status: common.Migrated, Matcher: name}
+		}
 	}
 }
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Matcher rewrites the lines matching its pattern.
+// Matchers are values built once, so their patterns are compiled at the initialization rather than on every line.
+type Matcher struct {
+	// Name identifies the matcher in the results
+	Name string
+	// Priority orders the matchers, the ones with the higher priority are tried first
+	Priority int
+	// Literal is the text every matching line contains, if any. It is checked before the pattern,
+	// so the lines which can't match are skipped cheaply.
+	Literal string
+	// Pattern is the compiled pattern of the line
+	Pattern *regexp.Regexp
+	// Rewrite receives the submatches of the pattern and returns the rewritten line,
+	// or empty string if the line is not supported after all.
+	Rewrite func(match []string) string
+}
+
+// Registry is an ordered set of matchers. It is immutable once built, so it's safe for concurrent use.
+type Registry struct {
+	matchers []Matcher
+	byName   map[string]int
+}
+
+// New builds the registry of the matchers ordered by the priority, the matchers of the same priority
+// keep the given order. It panics if the names are not unique, as registries are built
+// at the initialization from the matchers defined in the code.
+func New(matchers ...Matcher) *Registry {
+	r := &Registry{
+		matchers: append([]Matcher(nil), matchers...),
+		byName:   make(map[string]int, len(matchers)),
+	}
+	sort.SliceStable(r.matchers, func(i, j int) bool {
+		return r.matchers[i].Priority > r.matchers[j].Priority
+	})
+
+	for i, m := range r.matchers {
+		if _, ok := r.byName[m.Name]; ok {
+			panic(fmt.Sprintf("registry: duplicate matcher %q", m.Name))
+		}
+		r.byName[m.Name] = i
+	}
+
+	return r
+}
+
+// Match tries the matchers in the order of their priority and returns the name of the first one
+// rewriting the line along with the rewritten line, or empty strings if none does.
+func (r *Registry) Match(line string) (string, string) {
+	for _, m := range r.matchers {
+		if m.Literal != "" && !strings.Contains(line, m.Literal) {
+			continue
+		}
+
+		match := m.Pattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if rewritten := m.Rewrite(match); rewritten != "" {
+			return m.Name, rewritten
+		}
+	}
+
+	return "", ""
+}
+
+// Rewrite returns the line rewritten by the first matching matcher, or empty string if none matches.
+func (r *Registry) Rewrite(line string) string {
+	_, rewritten := r.Match(line)
+
+	return rewritten
+}
+
+// Lookup returns the matcher by its name.
+func (r *Registry) Lookup(name string) (Matcher, bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return Matcher{}, false
+	}
+
+	return r.matchers[i], true
+}
+
+// Matchers returns the matchers in the order they are tried.
+func (r *Registry) Matchers() []Matcher {
+	return append([]Matcher(nil), r.matchers...)
+}
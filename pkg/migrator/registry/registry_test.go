@@ -0,0 +1,113 @@
+package registry_test
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/migrator/registry"
+)
+
+func upper(match []string) string {
+	return strings.ToUpper(match[1])
+}
+
+func reject(match []string) string {
+	return ""
+}
+
+var matchers = []registry.Matcher{
+	{Name: "wrap", Priority: 1, Pattern: regexp.MustCompile(`^wrap (\w+)$`), Rewrite: upper},
+	{Name: "rejected", Priority: 3, Pattern: regexp.MustCompile(`^(\w+) \w+$`), Rewrite: reject},
+	{Name: "any", Priority: 0, Pattern: regexp.MustCompile(`^(.*)$`), Rewrite: upper},
+	{Name: "new", Priority: 2, Literal: "new", Pattern: regexp.MustCompile(`^(\w+) new$`), Rewrite: upper},
+}
+
+func TestRegistry(t *testing.T) {
+	r := registry.New(matchers...)
+
+	tests := []struct {
+		name            string
+		line            string
+		expectedMatcher string
+		expectedText    string
+	}{
+		{
+			name:            "Higher priority first",
+			line:            "errors new",
+			expectedMatcher: "new",
+			expectedText:    "ERRORS",
+		},
+		{
+			name:            "Rejected by the rewrite",
+			line:            "wrap err",
+			expectedMatcher: "wrap",
+			expectedText:    "ERR",
+		},
+		{
+			name:            "Fallback",
+			line:            "errors",
+			expectedMatcher: "any",
+			expectedText:    "ERRORS",
+		},
+		{
+			name:            "No match",
+			line:            "",
+			expectedMatcher: "",
+			expectedText:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, text := r.Match(tt.line)
+			assert.Equal(t, tt.expectedMatcher, matcher)
+			assert.Equal(t, tt.expectedText, text)
+		})
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	r := registry.New(matchers...)
+
+	var names []string
+	for _, m := range r.Matchers() {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"rejected", "new", "wrap", "any"}, names)
+
+	m, ok := r.Lookup("new")
+	assert.True(t, ok)
+	assert.Equal(t, 2, m.Priority)
+
+	_, ok = r.Lookup("missing")
+	assert.False(t, ok)
+}
+
+func TestNewDuplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		registry.New(matchers[0], matchers[0])
+	})
+}
+
+func TestConcurrentMatch(t *testing.T) {
+	r := registry.New(matchers...)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				matcher, text := r.Match("errors new")
+				assert.Equal(t, "new", matcher)
+				assert.Equal(t, "ERRORS", text)
+			}
+		}()
+	}
+	wg.Wait()
+}
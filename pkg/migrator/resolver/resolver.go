@@ -0,0 +1,236 @@
+package resolver
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrorsImportPath is the import path of the package being migrated from by default.
+const ErrorsImportPath = "github.com/pkg/errors"
+
+// Matches the major version suffix of the import path, e.g. "v2"
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// File describes how the package being migrated from is referred to in a Go file.
+type File struct {
+	// Name is the identifier the package is referred by, empty if the package is not imported.
+	Name string
+	// calls maps line numbers to byte offsets within the line of the package function invocations
+	calls map[int][]int
+	// ends maps line numbers to the last line of the invocations started on the line, if they span multiple lines
+	ends map[int]int
+	// sentinels maps positions of the invocations initializing package-level variables to the variable names
+	sentinels map[position]string
+	// commented holds positions of the invocations with comments inside
+	commented map[position]bool
+}
+
+// position locates an invocation by the line number and the byte offset within the line
+type position struct {
+	line   int
+	offset int
+}
+
+// Resolve parses the source of a Go file and finds all invocations of the functions of the package
+// imported by the source path, e.g. `github.com/pkg/errors`.
+func Resolve(src []byte, source string) (*File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution|parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not bother with the full parsing if the package is not imported
+	if _, name := FindImport(file, source); name == "" {
+		return &File{}, nil
+	}
+
+	file, err = parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	_, name := FindImport(file, source)
+	result := &File{
+		Name:      name,
+		calls:     map[int][]int{},
+		ends:      map[int]int{},
+		sentinels: map[position]string{},
+		commented: map[position]bool{},
+	}
+	for _, call := range FindCalls(file, name) {
+		pos, end := fset.Position(call.Pos()), fset.Position(call.End())
+		result.calls[pos.Line] = append(result.calls[pos.Line], pos.Column-1)
+
+		if end.Line > pos.Line {
+			result.ends[pos.Line] = max(result.ends[pos.Line], end.Line)
+		}
+		if ContainsComments(file, call) {
+			result.commented[position{line: pos.Line, offset: pos.Column - 1}] = true
+		}
+	}
+
+	for call, variable := range FindSentinels(file, name) {
+		pos := fset.Position(call.Pos())
+		result.sentinels[position{line: pos.Line, offset: pos.Column - 1}] = variable
+	}
+
+	return result, nil
+}
+
+// Calls returns byte offsets within the line of package function invocations started on the line.
+// Line numbers start from 1.
+func (f *File) Calls(line int) []int {
+	return f.calls[line]
+}
+
+// End returns the last line of the invocations started on the line, or the line itself
+// if none of them spans multiple lines.
+func (f *File) End(line int) int {
+	return max(line, f.ends[line])
+}
+
+// Sentinel returns the name of the package-level variable initialized by the invocation
+// started at the byte offset within the line, or empty string if the invocation isn't a sentinel declaration.
+func (f *File) Sentinel(line, offset int) string {
+	return f.sentinels[position{line: line, offset: offset}]
+}
+
+// HasComments reports whether the invocation started at the byte offset within the line has comments inside.
+// Comments can't be carried over by the line handlers, so such invocations are left to be migrated manually.
+func (f *File) HasComments(line, offset int) bool {
+	return f.commented[position{line: line, offset: offset}]
+}
+
+// CallsIn returns byte offsets within the text of package function invocations started on its lines.
+// The text holds consecutive lines of the file joined by "\n", starting from the first line.
+func (f *File) CallsIn(first int, text string) []int {
+	var offsets []int
+	start := 0
+	for line := first; ; line++ {
+		for _, offset := range f.calls[line] {
+			offsets = append(offsets, start+offset)
+		}
+
+		next := strings.IndexByte(text[start:], '\n')
+		if next == -1 {
+			return offsets
+		}
+		start += next + 1
+	}
+}
+
+// FindImport returns the import spec of the package imported by the source path and the name
+// it's referred by in the file. Dot and blank imports can't be referred by a selector, so they are ignored.
+func FindImport(file *ast.File, source string) (*ast.ImportSpec, string) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != source {
+			continue
+		}
+
+		if spec.Name == nil {
+			return spec, PackageName(source)
+		}
+
+		if spec.Name.Name == "." || spec.Name.Name == "_" {
+			return nil, ""
+		}
+
+		return spec, spec.Name.Name
+	}
+
+	return nil, ""
+}
+
+// PackageName returns the name of the package assumed from the import path, i.e. the last element of the path
+// which isn't the major version suffix.
+func PackageName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionRegex.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name
+}
+
+// ContainsComments reports whether there are comments within the node.
+func ContainsComments(file *ast.File, node ast.Node) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= node.Pos() && group.End() <= node.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindCalls returns all invocations of functions from the package imported as name, sorted by position.
+// The file must be parsed with object resolution enabled: identifiers resolved by the parser
+// to a local declaration (e.g. variable or parameter named `errors`) are not package references and are skipped.
+func FindCalls(file *ast.File, name string) []*ast.CallExpr {
+	var calls []*ast.CallExpr
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := sel.X.(*ast.Ident)
+		if ok && ident.Name == name && ident.Obj == nil {
+			calls = append(calls, call)
+		}
+
+		return true
+	})
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Pos() < calls[j].Pos() })
+
+	return calls
+}
+
+// FindSentinels returns invocations of functions from the package imported as name which initialize
+// package-level variables, e.g. `var ErrNotFound = errors.New("not found")`, mapped to the variable names.
+// Such variables are sentinel errors, compared by identity.
+func FindSentinels(file *ast.File, name string) map[*ast.CallExpr]string {
+	sentinels := map[*ast.CallExpr]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, s := range gen.Specs {
+			spec := s.(*ast.ValueSpec)
+			if len(spec.Names) != len(spec.Values) {
+				continue
+			}
+
+			for i, value := range spec.Values {
+				call, ok := ast.Unparen(value).(*ast.CallExpr)
+				if !ok || spec.Names[i].Name == "_" {
+					continue
+				}
+
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+					if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name && ident.Obj == nil {
+						sentinels[call] = spec.Names[i].Name
+					}
+				}
+			}
+		}
+	}
+
+	return sentinels
+}
@@ -0,0 +1,163 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/migrator/resolver"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		calls    map[int][]int
+	}{
+		{
+			name: "Standard library errors",
+			input: `package foo
+
+import "errors"
+
+func foo() error {
+	return errors.New("message")
+}
+`,
+			expected: "",
+			calls:    map[int][]int{6: nil},
+		},
+		{
+			name: "Plain import",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	return errors.Wrap(errors.New("message"), "wrapped")
+}
+`,
+			expected: "errors",
+			calls:    map[int][]int{6: {8, 20}},
+		},
+		{
+			name: "Aliased import",
+			input: `package foo
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func foo() error {
+	if errors.Is(err, ErrNotFound) {
+		return pkgerrors.New("message")
+	}
+	return nil
+}
+`,
+			expected: "pkgerrors",
+			calls:    map[int][]int{10: nil, 11: {9}},
+		},
+		{
+			name: "Local variable and parameter named errors",
+			input: `package foo
+
+import "github.com/pkg/errors"
+
+func foo(errors Collector) {
+	errors.Add(errors.New("message"))
+}
+
+func bar() error {
+	errors := newCollector()
+	errors.Add(nil)
+	return nil
+}
+
+func baz() error {
+	return errors.New("message")
+}
+`,
+			expected: "errors",
+			calls:    map[int][]int{6: nil, 11: nil, 16: {8}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := resolver.Resolve([]byte(tt.input), resolver.ErrorsImportPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, file.Name)
+			for line, calls := range tt.calls {
+				assert.Equal(t, calls, file.Calls(line), "line %d", line)
+			}
+		})
+	}
+}
+
+func TestResolveMultiLine(t *testing.T) {
+	src := `package foo
+
+import "github.com/pkg/errors"
+
+func foo() error {
+	if err != nil {
+		return errors.Wrap(
+			errors.New("message"),
+			"wrapped")
+	}
+	return errors.Wrapf(err, // comment
+		"failed %s", name)
+}
+`
+	file, err := resolver.Resolve([]byte(src), resolver.ErrorsImportPath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 9, file.End(7))
+	assert.Equal(t, 8, file.End(8))
+	assert.Equal(t, 12, file.End(11))
+	// Invocations with comments inside are reported
+	assert.False(t, file.HasComments(7, 9))
+	assert.True(t, file.HasComments(11, 8))
+
+	text := "\t\treturn errors.Wrap(\n\t\t\terrors.New(\"message\"),\n\t\t\t\"wrapped\")"
+	assert.Equal(t, []int{9, 25}, file.CallsIn(7, text))
+}
+
+func TestResolveSentinels(t *testing.T) {
+	src := `package foo
+
+import "github.com/pkg/errors"
+
+var ErrNotFound = errors.New("not found")
+
+var (
+	ErrExists, ErrTimeout = errors.New("exists"), errors.Errorf("timeout")
+	_                     = errors.New("ignored")
+)
+
+func foo() error {
+	var errLocal = errors.New("local")
+	return errLocal
+}
+`
+	file, err := resolver.Resolve([]byte(src), resolver.ErrorsImportPath)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "ErrNotFound", file.Sentinel(5, 18))
+	assert.Equal(t, "ErrExists", file.Sentinel(8, 25))
+	assert.Equal(t, "ErrTimeout", file.Sentinel(8, 47))
+	assert.Equal(t, "", file.Sentinel(9, 25))
+	// Local variables are not sentinels
+	assert.Equal(t, "", file.Sentinel(13, 16))
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "errors", resolver.PackageName("github.com/pkg/errors"))
+	assert.Equal(t, "errkit", resolver.PackageName("example.com/internal/errkit/v2"))
+	assert.Equal(t, "v2", resolver.PackageName("v2"))
+}
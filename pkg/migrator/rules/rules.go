@@ -0,0 +1,300 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mig/pkg/migrator/matcher_v2/mutators"
+)
+
+// Matches the metavariable name following the `$` sign
+var metavarRegex = regexp.MustCompile(`^[A-Za-z_]\w*`)
+
+// Rule rewrites the invocations matching the pattern into the replacement, gofmt -r style.
+// Both are invocations where `$name` metavariables stand for any argument, or any text within a string literal:
+//
+//	errors.Wrapf($err, "$msg app=%s", $x) -> errkit.Wrap($err, "$msg", "app", $x)
+//	errors.Errorf("$msg app=%s", $x) -> errkit.New("$msg", "app", $x)
+//
+// Patterns of the wrapping functions are replaced with errkit.Wrap, the ones of errors.Errorf and errors.New
+// with errkit.New. Errorf invocations wrapping errors with the %w verb can't be matched by the rules.
+type Rule struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+}
+
+// file is the layout of the rules file
+type file struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads the rules from the YAML file.
+func Load(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse parses the rules from the YAML document, e.g.
+//
+//	rules:
+//	  - name: app
+//	    pattern: 'errors.Wrapf($err, "$msg app=%s", $x)'
+//	    replacement: 'errkit.Wrap($err, "$msg", "app", $x)'
+func Parse(data []byte) ([]Rule, error) {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	return f.Rules, nil
+}
+
+// Compile turns the rules into the matchers of the functions their patterns invoke, keeping the order of the rules.
+// Patterns have to invoke one of mutators.MatchedFuncs of the `errors` package. Patterns of the wrapping
+// functions take the metavariable as the wrapped error, and their replacements have to wrap the same
+// metavariable with errkit.Wrap. Replacements of mutators.NewFuncs have to invoke errkit.New.
+// The invocations are written with the default qualifiers, the configured ones are used for the migrated code.
+func Compile(rules []Rule) (map[string][]mutators.MatcherFn, error) {
+	matchers := map[string][]mutators.MatcherFn{}
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		funcName, matcher, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		matchers[funcName] = append(matchers[funcName], matcher)
+	}
+
+	return matchers, nil
+}
+
+// LoadMatchers reads the rules from the YAML file and compiles them.
+func LoadMatchers(path string) (map[string][]mutators.MatcherFn, error) {
+	rules, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(rules)
+}
+
+// compile returns the function the rule applies to and the matcher of the arguments following the wrapped error,
+// or of all the arguments for mutators.NewFuncs.
+func compile(rule Rule) (string, mutators.MatcherFn, error) {
+	pkg, funcName, patternArgs, err := splitCall(rule.Pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if pkg != "errors" || !slices.Contains(mutators.MatchedFuncs, funcName) {
+		return "", nil, fmt.Errorf("pattern has to invoke one of errors.%s", strings.Join(mutators.MatchedFuncs, ", errors."))
+	}
+
+	pkg, replacementFunc, replacementArgs, err := splitCall(rule.Replacement)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid replacement: %w", err)
+	}
+
+	var matched, templates []string
+	if slices.Contains(mutators.NewFuncs, funcName) {
+		if pkg != "errkit" || replacementFunc != "New" {
+			return "", nil, fmt.Errorf("replacement of errors.%s has to invoke errkit.New", funcName)
+		}
+		if len(patternArgs) == 0 || len(replacementArgs) == 0 {
+			return "", nil, fmt.Errorf("both pattern and replacement have to pass the message")
+		}
+		for _, arg := range patternArgs {
+			if strings.Contains(arg, "%w") {
+				return "", nil, fmt.Errorf("pattern can't wrap errors with the %%w verb, errkit.New doesn't wrap them")
+			}
+		}
+		matched, templates = patternArgs, replacementArgs
+	} else {
+		if pkg != "errkit" || replacementFunc != "Wrap" {
+			return "", nil, fmt.Errorf("replacement of errors.%s has to invoke errkit.Wrap", funcName)
+		}
+		if len(patternArgs) < 2 || !isMetavar(patternArgs[0]) {
+			return "", nil, fmt.Errorf("pattern has to wrap the error given by a metavariable, e.g. $err")
+		}
+		if len(replacementArgs) < 2 || replacementArgs[0] != patternArgs[0] {
+			return "", nil, fmt.Errorf("replacement has to wrap %s", patternArgs[0])
+		}
+		matched, templates = patternArgs[1:], replacementArgs[1:]
+	}
+
+	args := make([]*argPattern, 0, len(matched))
+	bound := map[string]bool{}
+	for _, arg := range matched {
+		p, err := compileArg(arg)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid pattern argument %s: %w", arg, err)
+		}
+		for _, name := range p.names {
+			bound[name] = true
+		}
+		args = append(args, p)
+	}
+
+	for _, arg := range templates {
+		for _, name := range metavars(arg) {
+			if !bound[name] {
+				return "", nil, fmt.Errorf("metavariable $%s of the replacement is not bound by the pattern", name)
+			}
+		}
+	}
+
+	return funcName, func(input []string) []string {
+		if len(input) != len(args) {
+			return nil
+		}
+
+		bindings := map[string]string{}
+		for i, arg := range args {
+			if !arg.match(input[i], bindings) {
+				return nil
+			}
+		}
+
+		result := make([]string, 0, len(templates))
+		for _, arg := range templates {
+			result = append(result, substitute(arg, bindings))
+		}
+
+		return result
+	}, nil
+}
+
+// splitCall splits the invocation into the package name, the function name and the arguments.
+func splitCall(call string) (string, string, []string, error) {
+	call = strings.TrimSpace(call)
+	if !strings.HasSuffix(call, ")") {
+		return "", "", nil, fmt.Errorf("%q is not an invocation", call)
+	}
+
+	return mutators.SplitCall(call)
+}
+
+// argPattern matches the argument text, binding the metavariables
+type argPattern struct {
+	re    *regexp.Regexp
+	names []string // metavariable names by the regexp group
+}
+
+// compileArg compiles the pattern argument. Metavariables outside string literals match any non-empty text,
+// the ones inside string literals match any text, including the empty one.
+func compileArg(arg string) (*argPattern, error) {
+	p := &argPattern{}
+	expr := strings.Builder{}
+	expr.WriteString(`^`)
+	err := scan(arg, func(literal string) {
+		expr.WriteString(regexp.QuoteMeta(literal))
+	}, func(name string, inString bool) {
+		p.names = append(p.names, name)
+		if inString {
+			expr.WriteString(`(.*?)`)
+		} else {
+			expr.WriteString(`(.+?)`)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	expr.WriteString(`$`)
+
+	p.re, err = regexp.Compile(expr.String())
+
+	return p, err
+}
+
+// match matches the argument, adding the bindings of its metavariables.
+// A metavariable used several times has to be bound to the same text.
+func (p *argPattern) match(arg string, bindings map[string]string) bool {
+	groups := p.re.FindStringSubmatch(strings.TrimSpace(arg))
+	if groups == nil {
+		return false
+	}
+
+	for i, name := range p.names {
+		value := groups[i+1]
+		if bound, ok := bindings[name]; ok && bound != value {
+			return false
+		}
+		bindings[name] = value
+	}
+
+	return true
+}
+
+// substitute replaces the metavariables of the replacement argument with the texts bound to them.
+func substitute(arg string, bindings map[string]string) string {
+	result := strings.Builder{}
+	// The replacement is validated by the compilation, so scan can't fail
+	_ = scan(arg, func(literal string) {
+		result.WriteString(literal)
+	}, func(name string, _ bool) {
+		result.WriteString(bindings[name])
+	})
+
+	return result.String()
+}
+
+// metavars returns the names of the metavariables of the argument.
+func metavars(arg string) []string {
+	var names []string
+	_ = scan(arg, func(string) {}, func(name string, _ bool) {
+		names = append(names, name)
+	})
+
+	return names
+}
+
+// isMetavar reports whether the argument is a single metavariable outside string literals.
+func isMetavar(arg string) bool {
+	return strings.HasPrefix(arg, "$") && metavarRegex.FindString(arg[1:]) == arg[1:]
+}
+
+// scan splits the argument into literal texts and metavariables, reporting whether each metavariable
+// is inside a string literal. A `$` sign not followed by a name is a literal.
+func scan(arg string, literal func(string), metavar func(name string, inString bool)) error {
+	var quote byte
+	start := 0
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		switch {
+		case quote != 0 && c == '\\' && quote != '`':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '`' || c == '\''):
+			quote = c
+		case c == '$':
+			name := metavarRegex.FindString(arg[i+1:])
+			if name == "" {
+				continue
+			}
+			literal(arg[start:i])
+			metavar(name, quote != 0)
+			i += len(name)
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return fmt.Errorf("unclosed string literal")
+	}
+	literal(arg[start:])
+
+	return nil
+}
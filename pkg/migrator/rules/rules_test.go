@@ -0,0 +1,204 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/migrator/rules"
+)
+
+const rulesFile = `
+rules:
+  - name: app
+    pattern: 'errors.Wrapf($err, "$msg app=%s", $x)'
+    replacement: 'errkit.Wrap($err, "$msg", "app", $x)'
+  - pattern: 'errors.Wrap($err, $msg)'
+    replacement: 'errkit.Wrap($err, $msg)'
+`
+
+func TestParse(t *testing.T) {
+	got, err := rules.Parse([]byte(rulesFile))
+	assert.NoError(t, err)
+	assert.Equal(t, []rules.Rule{
+		{
+			Name:        "app",
+			Pattern:     `errors.Wrapf($err, "$msg app=%s", $x)`,
+			Replacement: `errkit.Wrap($err, "$msg", "app", $x)`,
+		},
+		{
+			Pattern:     `errors.Wrap($err, $msg)`,
+			Replacement: `errkit.Wrap($err, $msg)`,
+		},
+	}, got)
+
+	_, err = rules.Parse([]byte("rules: {"))
+	assert.Error(t, err)
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     rules.Rule
+		funcName string
+		input    []string
+		expected []string
+	}{
+		{
+			name: "Metavariables within string literal",
+			rule: rules.Rule{
+				Pattern:     `errors.Wrapf($err, "$msg app=%s", $x)`,
+				Replacement: `errkit.Wrap($err, "$msg", "app", $x)`,
+			},
+			funcName: "Wrapf",
+			input:    []string{`"Failed to install app=%s"`, `app.Name`},
+			expected: []string{`"Failed to install"`, `"app"`, `app.Name`},
+		},
+		{
+			name: "Empty text bound to metavariable within string literal",
+			rule: rules.Rule{
+				Pattern:     `errors.Wrapf($err, "$msg app=%s", $x)`,
+				Replacement: `errkit.Wrap($err, "$msg", "app", $x)`,
+			},
+			funcName: "Wrapf",
+			input:    []string{`" app=%s"`, `name`},
+			expected: []string{`""`, `"app"`, `name`},
+		},
+		{
+			name: "Literal arguments",
+			rule: rules.Rule{
+				Pattern:     `errors.Wrap($err, "Failed to connect")`,
+				Replacement: `errkit.Wrap($err, "Failed to connect", "retryable", true)`,
+			},
+			funcName: "Wrap",
+			input:    []string{`"Failed to connect"`},
+			expected: []string{`"Failed to connect"`, `"retryable"`, `true`},
+		},
+		{
+			name: "Metavariable used twice",
+			rule: rules.Rule{
+				Pattern:     `errors.WithMessagef($err, "$op %s", $op)`,
+				Replacement: `errkit.Wrap($err, "Operation failed", "op", $op)`,
+			},
+			funcName: "WithMessagef",
+			input:    []string{`"delete %s"`, `delete`},
+			expected: []string{`"Operation failed"`, `"op"`, `delete`},
+		},
+		{
+			name: "Errorf",
+			rule: rules.Rule{
+				Pattern:     `errors.Errorf("$msg app=%s", $x)`,
+				Replacement: `errkit.New("$msg", "app", $x)`,
+			},
+			funcName: "Errorf",
+			input:    []string{`"Unknown app=%s"`, `app.Name`},
+			expected: []string{`"Unknown"`, `"app"`, `app.Name`},
+		},
+		{
+			name: "New",
+			rule: rules.Rule{
+				Pattern:     `errors.New("Failed to connect")`,
+				Replacement: `errkit.New("Failed to connect", "retryable", true)`,
+			},
+			funcName: "New",
+			input:    []string{`"Failed to connect"`},
+			expected: []string{`"Failed to connect"`, `"retryable"`, `true`},
+		},
+		{
+			name: "No match - metavariable bound to different texts",
+			rule: rules.Rule{
+				Pattern:     `errors.WithMessagef($err, "$op %s", $op)`,
+				Replacement: `errkit.Wrap($err, "Operation failed", "op", $op)`,
+			},
+			funcName: "WithMessagef",
+			input:    []string{`"delete %s"`, `create`},
+			expected: nil,
+		},
+		{
+			name: "No match - different text",
+			rule: rules.Rule{
+				Pattern:     `errors.Wrapf($err, "$msg app=%s", $x)`,
+				Replacement: `errkit.Wrap($err, "$msg", "app", $x)`,
+			},
+			funcName: "Wrapf",
+			input:    []string{`"Failed to install pod=%s"`, `pod`},
+			expected: nil,
+		},
+		{
+			name: "No match - different number of arguments",
+			rule: rules.Rule{
+				Pattern:     `errors.Wrapf($err, "$msg app=%s", $x)`,
+				Replacement: `errkit.Wrap($err, "$msg", "app", $x)`,
+			},
+			funcName: "Wrapf",
+			input:    []string{`"Failed to install app=%s %s"`, `app`, `ns`},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			matchers, err := rules.Compile([]rules.Rule{tt.rule})
+			assert.NoError(t, err)
+			assert.Len(t, matchers[tt.funcName], 1)
+			assert.Equal(t, tt.expected, matchers[tt.funcName][0](tt.input))
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule rules.Rule
+	}{
+		{
+			name: "Unsupported function",
+			rule: rules.Rule{Pattern: `errors.Cause($err)`, Replacement: `errkit.Wrap($err, "cause")`},
+		},
+		{
+			name: "Errorf replacement is not errkit.New",
+			rule: rules.Rule{Pattern: `errors.Errorf("$msg %s", $x)`, Replacement: `errkit.Wrap($x, "$msg")`},
+		},
+		{
+			name: "Errorf wrapping error",
+			rule: rules.Rule{Pattern: `errors.Errorf("$msg: %w", $err)`, Replacement: `errkit.New("$msg", "cause", $err)`},
+		},
+		{
+			name: "Wrap replacement is errkit.New",
+			rule: rules.Rule{Pattern: `errors.Wrap($err, $msg)`, Replacement: `errkit.New($msg)`},
+		},
+		{
+			name: "Not an invocation",
+			rule: rules.Rule{Pattern: `errors.Wrap`, Replacement: `errkit.Wrap($err, "msg")`},
+		},
+		{
+			name: "Wrapped error is not a metavariable",
+			rule: rules.Rule{Pattern: `errors.Wrap(err, $msg)`, Replacement: `errkit.Wrap(err, $msg)`},
+		},
+		{
+			name: "Replacement is not errkit.Wrap",
+			rule: rules.Rule{Pattern: `errors.Wrap($err, $msg)`, Replacement: `errkit.WithStack($err)`},
+		},
+		{
+			name: "Replacement wraps another error",
+			rule: rules.Rule{Pattern: `errors.Wrap($err, $msg)`, Replacement: `errkit.Wrap($other, $msg)`},
+		},
+		{
+			name: "Unbound metavariable",
+			rule: rules.Rule{Pattern: `errors.Wrap($err, $msg)`, Replacement: `errkit.Wrap($err, $msg, "app", $x)`},
+		},
+		{
+			name: "Unclosed string literal",
+			rule: rules.Rule{Pattern: `errors.Wrap($err, "$msg)`, Replacement: `errkit.Wrap($err, $msg)`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rules.Compile([]rules.Rule{tt.rule})
+			assert.Error(t, err)
+		})
+	}
+}
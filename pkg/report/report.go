@@ -0,0 +1,173 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/traverser"
+)
+
+// File statuses
+const (
+	StatusUnchanged   = "unchanged"
+	StatusMigrated    = "migrated"
+	StatusNeedsManual = "needs_manual"
+	StatusFailed      = "failed"
+	StatusReverted    = "reverted"
+)
+
+// revertedReason is the reason of the migrated calls of the files reverted by the verification
+const revertedReason = "the file is reverted as its migration introduces type errors"
+
+// Report is the machine-readable outcome of a migration run.
+type Report struct {
+	// Root is the path the files were walked from, the file paths start with it
+	Root    string  `json:"-"`
+	Summary Summary `json:"summary"`
+	Files   []File  `json:"files"`
+	// Sentinels are the package-level error variables found, so the way they are compared can be audited
+	Sentinels []Sentinel `json:"sentinels,omitempty"`
+}
+
+// Summary holds the totals of the run.
+type Summary struct {
+	Files       int `json:"files"`
+	Changed     int `json:"changed"`
+	NeedsManual int `json:"needs_manual"`
+	Failed      int `json:"failed"`
+	Reverted    int `json:"reverted"`
+	// MigratedCalls and ManualCalls count call sites across all files
+	MigratedCalls int `json:"migrated_calls"`
+	ManualCalls   int `json:"manual_calls"`
+}
+
+// File is the outcome of processing a single file.
+type File struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Diagnostics are the type errors introduced by the migration of the reverted file
+	Diagnostics []string `json:"diagnostics,omitempty"`
+	Calls       []Call   `json:"calls,omitempty"`
+}
+
+// Call is the outcome of handling a single call site.
+type Call struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Func      string `json:"func,omitempty"`
+	Original  string `json:"original"`
+	Rewritten string `json:"rewritten"`
+	Matcher   string `json:"matcher,omitempty"`
+	// Manual is set if the call site was left to be migrated manually
+	Manual bool   `json:"manual"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sentinel is a package-level error variable declaration.
+type Sentinel struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Name      string `json:"name"`
+	Original  string `json:"original"`
+	Rewritten string `json:"rewritten"`
+	Manual    bool   `json:"manual"`
+}
+
+// New builds the report from the results of the traversal of the root.
+func New(root string, results []traverser.FileResult) Report {
+	report := Report{Root: root, Files: make([]File, 0, len(results))}
+	for _, r := range results {
+		file := File{Path: r.Path, Status: fileStatus(r), Diagnostics: r.Diagnostics}
+		if r.Err != nil {
+			file.Error = r.Err.Error()
+		}
+
+		for _, c := range r.Calls {
+			manual, reason := callManual(r, c)
+			file.Calls = append(file.Calls, Call{
+				Line:      c.Line,
+				Column:    c.Column,
+				Func:      c.Func,
+				Original:  c.Original,
+				Rewritten: c.Rewritten,
+				Matcher:   c.Matcher,
+				Manual:    manual,
+				Reason:    reason,
+			})
+
+			if c.Sentinel != "" {
+				report.Sentinels = append(report.Sentinels, Sentinel{
+					Path:      r.Path,
+					Line:      c.Line,
+					Name:      c.Sentinel,
+					Original:  c.Original,
+					Rewritten: c.Rewritten,
+					Manual:    manual,
+				})
+			}
+		}
+
+		report.Files = append(report.Files, file)
+		report.Summary.add(r)
+	}
+
+	return report
+}
+
+func (s *Summary) add(r traverser.FileResult) {
+	s.Files++
+	switch {
+	case r.Err != nil:
+		s.Failed++
+	case r.Reverted:
+		s.Reverted++
+	case r.Status == common.NeedsManual:
+		s.Changed++
+		s.NeedsManual++
+	case r.Status == common.Migrated:
+		s.Changed++
+	}
+
+	if r.Reverted {
+		// Nothing is written for the reverted files, so all their calls are left to be migrated manually
+		s.ManualCalls += r.Count(common.Migrated) + r.Count(common.NeedsManual)
+		return
+	}
+	s.MigratedCalls += r.Count(common.Migrated)
+	s.ManualCalls += r.Count(common.NeedsManual)
+}
+
+// callManual reports whether the call site is left to be migrated manually and why.
+// The migrated calls of the reverted files are, as the files are left as they are.
+func callManual(r traverser.FileResult, c common.Call) (bool, string) {
+	if r.Reverted && c.Status == common.Migrated {
+		return true, revertedReason
+	}
+
+	return c.Status == common.NeedsManual, c.Reason
+}
+
+func fileStatus(r traverser.FileResult) string {
+	switch {
+	case r.Err != nil:
+		return StatusFailed
+	case r.Reverted:
+		return StatusReverted
+	case r.Status == common.NeedsManual:
+		return StatusNeedsManual
+	case r.Status == common.Migrated:
+		return StatusMigrated
+	default:
+		return StatusUnchanged
+	}
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
@@ -0,0 +1,158 @@
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/report"
+	"mig/pkg/traverser"
+)
+
+func TestWriteJSON(t *testing.T) {
+	results := []traverser.FileResult{
+		{
+			Path:   "pkg/foo/foo.go",
+			Status: common.NeedsManual,
+			Calls: []common.Call{
+				{
+					Line:      10,
+					Column:    9,
+					Original:  `errors.Wrap(err, "Failed to get secrets")`,
+					Rewritten: `errkit.Wrap(err, "Failed to get secrets")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleWrap",
+				},
+				{
+					Line:      5,
+					Column:    19,
+					Func:      "New",
+					Original:  `errors.New("Not found")`,
+					Rewritten: `errkit.NewSentinelErr("Not found")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleNewSentinel",
+					Sentinel:  "ErrNotFound",
+				},
+				{
+					Line:      12,
+					Column:    9,
+					Original:  `errors.Cause(err)`,
+					Rewritten: `errors.Cause(err)`,
+					Status:    common.NeedsManual,
+					Reason:    "errors.Cause is not supported",
+				},
+			},
+		},
+		{
+			Path: "pkg/foo/bar.go",
+		},
+		{
+			Path: "pkg/foo/baz.go",
+			Err:  errors.New("expected 'package', found 'EOF'"),
+		},
+		{
+			Path:        "pkg/foo/qux.go",
+			Status:      common.Migrated,
+			Reverted:    true,
+			Diagnostics: []string{`pkg/foo/qux.go:3:8: could not import github.com/kanisterio/errkit`},
+			Calls: []common.Call{
+				{
+					Line:      7,
+					Column:    9,
+					Original:  `errors.Wrap(err, "Failed to get PVC")`,
+					Rewritten: `errkit.Wrap(err, "Failed to get PVC")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleWrap",
+				},
+			},
+		},
+	}
+
+	buf := bytes.Buffer{}
+	err := report.WriteJSON(&buf, report.New("pkg", results))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "summary": {
+    "files": 4,
+    "changed": 1,
+    "needs_manual": 1,
+    "failed": 1,
+    "reverted": 1,
+    "migrated_calls": 2,
+    "manual_calls": 2
+  },
+  "files": [
+    {
+      "path": "pkg/foo/foo.go",
+      "status": "needs_manual",
+      "calls": [
+        {
+          "line": 10,
+          "column": 9,
+          "original": "errors.Wrap(err, \"Failed to get secrets\")",
+          "rewritten": "errkit.Wrap(err, \"Failed to get secrets\")",
+          "matcher": "HandleWrap",
+          "manual": false
+        },
+        {
+          "line": 5,
+          "column": 19,
+          "func": "New",
+          "original": "errors.New(\"Not found\")",
+          "rewritten": "errkit.NewSentinelErr(\"Not found\")",
+          "matcher": "HandleNewSentinel",
+          "manual": false
+        },
+        {
+          "line": 12,
+          "column": 9,
+          "original": "errors.Cause(err)",
+          "rewritten": "errors.Cause(err)",
+          "manual": true,
+          "reason": "errors.Cause is not supported"
+        }
+      ]
+    },
+    {
+      "path": "pkg/foo/bar.go",
+      "status": "unchanged"
+    },
+    {
+      "path": "pkg/foo/baz.go",
+      "status": "failed",
+      "error": "expected 'package', found 'EOF'"
+    },
+    {
+      "path": "pkg/foo/qux.go",
+      "status": "reverted",
+      "diagnostics": [
+        "pkg/foo/qux.go:3:8: could not import github.com/kanisterio/errkit"
+      ],
+      "calls": [
+        {
+          "line": 7,
+          "column": 9,
+          "original": "errors.Wrap(err, \"Failed to get PVC\")",
+          "rewritten": "errkit.Wrap(err, \"Failed to get PVC\")",
+          "matcher": "HandleWrap",
+          "manual": true,
+          "reason": "the file is reverted as its migration introduces type errors"
+        }
+      ]
+    }
+  ],
+  "sentinels": [
+    {
+      "path": "pkg/foo/foo.go",
+      "line": 5,
+      "name": "ErrNotFound",
+      "original": "errors.New(\"Not found\")",
+      "rewritten": "errkit.NewSentinelErr(\"Not found\")",
+      "manual": false
+    }
+  ]
+}`, buf.String())
+}
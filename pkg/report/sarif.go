@@ -0,0 +1,181 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "migr"
+
+	// manualRuleID is used for call sites of unknown functions
+	manualRuleID = "manual"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int          `json:"startLine"`
+	StartColumn int          `json:"startColumn"`
+	Snippet     sarifMessage `json:"snippet"`
+}
+
+// WriteSARIF writes the call sites which have to be migrated manually as SARIF 2.1.0 log.
+// Every `errors` package function gets its own rule.
+func WriteSARIF(w io.Writer, report Report) error {
+	var manual []sarifResult
+	rules := map[string]sarifRule{}
+	for _, file := range report.Files {
+		for _, call := range file.Calls {
+			if !call.Manual {
+				continue
+			}
+
+			rule := ruleFor(call)
+			rules[rule.ID] = rule
+
+			message := rule.ShortDescription.Text
+			if call.Reason != "" {
+				message = call.Reason
+			}
+
+			manual = append(manual, sarifResult{
+				RuleID:  rule.ID,
+				Level:   "warning",
+				Message: sarifMessage{Text: message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: fileURI(report.Root, file.Path)},
+						Region: sarifRegion{
+							StartLine:   call.Line,
+							StartColumn: call.Column,
+							Snippet:     sarifMessage{Text: call.Original},
+						},
+					},
+				}},
+			})
+		}
+	}
+
+	// Keep the rules order stable and refer to them by index
+	ruleList := make([]sarifRule, 0, len(rules))
+	for _, rule := range rules {
+		ruleList = append(ruleList, rule)
+	}
+	sort.Slice(ruleList, func(i, j int) bool { return ruleList[i].ID < ruleList[j].ID })
+
+	ruleIndex := map[string]int{}
+	for i, rule := range ruleList {
+		ruleIndex[rule.ID] = i
+	}
+	for i := range manual {
+		manual[i].RuleIndex = ruleIndex[manual[i].RuleID]
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: ruleList}},
+			Results: manual,
+		}},
+	}
+	if log.Runs[0].Results == nil {
+		log.Runs[0].Results = []sarifResult{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(log)
+}
+
+// ruleFor returns the rule reported for the call site.
+func ruleFor(call Call) sarifRule {
+	if call.Func == "" {
+		return sarifRule{
+			ID:               manualRuleID,
+			Name:             "ManualMigration",
+			ShortDescription: sarifMessage{Text: "Code has to be migrated to errkit manually"},
+		}
+	}
+
+	return sarifRule{
+		ID:               call.Func,
+		Name:             "Manual" + call.Func + "Migration",
+		ShortDescription: sarifMessage{Text: fmt.Sprintf("errors.%s call has to be migrated to errkit manually", call.Func)},
+	}
+}
+
+// fileURI returns the URI of the file relative to the root, or its name if the root is the file itself,
+// so the results point at the files regardless of the working directory. Files outside of the root
+// get the absolute file URI.
+func fileURI(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if rel == "." {
+			rel = filepath.Base(path)
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	return "file://" + filepath.ToSlash(path)
+}
@@ -0,0 +1,190 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	common "mig/pkg/migrator/common"
+	"mig/pkg/report"
+	"mig/pkg/traverser"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	results := []traverser.FileResult{
+		{
+			Path:   "pkg/foo/foo.go",
+			Status: common.NeedsManual,
+			Calls: []common.Call{
+				{
+					Line:      10,
+					Column:    9,
+					Func:      "Wrap",
+					Original:  `errors.Wrap(err, "Failed to get secrets")`,
+					Rewritten: `errkit.Wrap(err, "Failed to get secrets")`,
+					Status:    common.Migrated,
+					Matcher:   "HandleWrap",
+				},
+				{
+					Line:      12,
+					Column:    9,
+					Func:      "Wrapf",
+					Original:  `errors.Wrapf(err, "%s %s", a, b)`,
+					Rewritten: `errors.Wrapf(err, "%s %s", a, b)`,
+					Status:    common.NeedsManual,
+					Matcher:   "HandleWrapf",
+					Reason:    "arguments of errors.Wrapf can't be migrated automatically",
+				},
+				{
+					Line:      14,
+					Column:    5,
+					Func:      "Cause",
+					Original:  `errors.Cause(err)`,
+					Rewritten: `errors.Cause(err)`,
+					Status:    common.NeedsManual,
+					Reason:    "errors.Cause is not supported",
+				},
+			},
+		},
+		{
+			Path: "pkg/foo/bar.go",
+		},
+	}
+
+	buf := bytes.Buffer{}
+	err := report.WriteSARIF(&buf, report.New("pkg", results))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "migr",
+          "rules": [
+            {
+              "id": "Cause",
+              "name": "ManualCauseMigration",
+              "shortDescription": {"text": "errors.Cause call has to be migrated to errkit manually"}
+            },
+            {
+              "id": "Wrapf",
+              "name": "ManualWrapfMigration",
+              "shortDescription": {"text": "errors.Wrapf call has to be migrated to errkit manually"}
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "Wrapf",
+          "ruleIndex": 1,
+          "level": "warning",
+          "message": {"text": "arguments of errors.Wrapf can't be migrated automatically"},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "foo/foo.go"},
+                "region": {
+                  "startLine": 12,
+                  "startColumn": 9,
+                  "snippet": {"text": "errors.Wrapf(err, \"%s %s\", a, b)"}
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "Cause",
+          "ruleIndex": 0,
+          "level": "warning",
+          "message": {"text": "errors.Cause is not supported"},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "foo/foo.go"},
+                "region": {
+                  "startLine": 14,
+                  "startColumn": 5,
+                  "snippet": {"text": "errors.Cause(err)"}
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`, buf.String())
+}
+
+func TestWriteSARIFURI(t *testing.T) {
+	abs, err := filepath.Abs(filepath.Join("pkg", "foo", "foo.go"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		root     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "Relative root",
+			root:     "./pkg",
+			path:     "pkg/foo/foo.go",
+			expected: "foo/foo.go",
+		},
+		{
+			name:     "Absolute root",
+			root:     filepath.Dir(filepath.Dir(abs)),
+			path:     abs,
+			expected: "foo/foo.go",
+		},
+		{
+			name:     "Root is the file",
+			root:     "pkg/foo/foo.go",
+			path:     "pkg/foo/foo.go",
+			expected: "foo.go",
+		},
+		{
+			name:     "File outside of the root",
+			root:     "pkg/bar",
+			path:     "pkg/foo/foo.go",
+			expected: "file://" + filepath.ToSlash(abs),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			results := []traverser.FileResult{{
+				Path:   tt.path,
+				Status: common.NeedsManual,
+				Calls:  []common.Call{{Line: 1, Column: 1, Original: `errors.Cause(err)`, Status: common.NeedsManual}},
+			}}
+
+			buf := bytes.Buffer{}
+			assert.NoError(t, report.WriteSARIF(&buf, report.New(tt.root, results)))
+
+			var log struct {
+				Runs []struct {
+					Results []struct {
+						Locations []struct {
+							PhysicalLocation struct {
+								ArtifactLocation struct {
+									URI string `json:"uri"`
+								} `json:"artifactLocation"`
+							} `json:"physicalLocation"`
+						} `json:"locations"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+			assert.Equal(t, tt.expected, log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		})
+	}
+}
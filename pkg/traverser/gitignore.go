@@ -0,0 +1,196 @@
+package traverser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitignoreFileName is the name of the files listing the paths ignored by git
+const GitignoreFileName = ".gitignore"
+
+// Gitignore is the set of rules of a `.gitignore` file, applying to the paths under its directory.
+type Gitignore struct {
+	// Dir is the directory the rules are relative to
+	Dir   string
+	rules []ignoreRule
+}
+
+// ignoreRule is a single pattern of the `.gitignore` file
+type ignoreRule struct {
+	// segments are the slash separated glob segments, prefixed by `**` unless the pattern is anchored to the directory
+	segments []string
+	// negate re-includes the paths ignored by the preceding rules
+	negate bool
+	// dirOnly matches directories only
+	dirOnly bool
+}
+
+// ParseGitignore parses the rules of the `.gitignore` file of the directory.
+// Patterns follow the gitignore format: blank lines and `#` comments are skipped, `!` negates the pattern,
+// a trailing `/` matches directories only, and a pattern containing a slash is relative to the directory,
+// otherwise it matches the name at any level.
+func ParseGitignore(dir string, data []byte) *Gitignore {
+	g := &Gitignore{Dir: dir}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		rule.segments = strings.Split(line, "/")
+		g.rules = append(g.rules, rule)
+	}
+
+	return g
+}
+
+// LoadGitignore reads the `.gitignore` file of the directory. It returns nil if there is none.
+func LoadGitignore(dir string) (*Gitignore, error) {
+	return loadIgnoreFile(dir, filepath.Join(dir, GitignoreFileName))
+}
+
+// loadIgnoreFile reads the ignore rules relative to the directory from the file, nil if there is none.
+func loadIgnoreFile(dir, path string) (*Gitignore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGitignore(dir, data), nil
+}
+
+// Match reports whether the path under the directory is matched by some of the rules and, if it is,
+// whether it is ignored. The last matching rule wins.
+func (g *Gitignore) Match(p string, isDir bool) (ignored, matched bool) {
+	rel, err := filepath.Rel(g.Dir, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, false
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+
+	for i := len(g.rules) - 1; i >= 0; i-- {
+		rule := g.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, segments) {
+			return !rule.negate, true
+		}
+	}
+
+	return false, false
+}
+
+// ignoreChain holds the rules of the `.gitignore` files of the directory being walked and its parents,
+// the deepest last.
+type ignoreChain []*Gitignore
+
+// Ignored reports whether the path is ignored. The rules of the deeper files take precedence.
+func (c ignoreChain) Ignored(p string, isDir bool) bool {
+	for i := len(c) - 1; i >= 0; i-- {
+		if ignored, matched := c[i].Match(p, isDir); matched {
+			return ignored
+		}
+	}
+
+	return false
+}
+
+// enter returns the chain applying to the entries of the directory, loading its `.gitignore` file.
+// Rules of the directories which are not parents of the directory are dropped.
+func (c ignoreChain) enter(dir string) (ignoreChain, error) {
+	for len(c) > 0 && !within(c[len(c)-1].Dir, dir) {
+		c = c[:len(c)-1]
+	}
+
+	g, err := LoadGitignore(dir)
+	if err != nil || g == nil {
+		return c, err
+	}
+
+	return append(c[:len(c):len(c)], g), nil
+}
+
+// rootIgnoreChain returns the rules applying to the root from outside of it, i.e. those of the git repository
+// exclude file and of the `.gitignore` files of the parent directories within the repository.
+func rootIgnoreChain(root string) (ignoreChain, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parent directories up to the repository root, the closest first
+	var parents []string
+	repo := ""
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			repo = dir
+		}
+		parents = append(parents, dir)
+		if repo != "" || filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+		repo, parents = abs, nil
+	}
+	if repo == "" {
+		return nil, nil
+	}
+
+	var chain ignoreChain
+	exclude, err := loadIgnoreFile(repo, filepath.Join(repo, ".git", "info", "exclude"))
+	if err != nil {
+		return nil, err
+	}
+	if exclude != nil {
+		chain = append(chain, exclude)
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		g, err := LoadGitignore(parents[i])
+		if err != nil {
+			return nil, err
+		}
+		if g != nil {
+			chain = append(chain, g)
+		}
+	}
+
+	return chain, nil
+}
+
+// within reports whether the path is the directory or is under it.
+func within(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
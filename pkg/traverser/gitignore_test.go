@@ -0,0 +1,57 @@
+package traverser_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/traverser"
+)
+
+func TestGitignore(t *testing.T) {
+	dir := t.TempDir()
+	g := traverser.ParseGitignore(dir, []byte(`
+# Build output
+/bin
+build/
+*.pb.go
+!keep.pb.go
+pkg/**/mocks
+\#hash.go
+trailing.go
+`))
+
+	tests := []struct {
+		path            string
+		isDir           bool
+		expectedIgnored bool
+		expectedMatched bool
+	}{
+		{path: "bin", isDir: true, expectedIgnored: true, expectedMatched: true},
+		{path: "cmd/bin", isDir: true, expectedIgnored: false, expectedMatched: false},
+		{path: "cmd/build", isDir: true, expectedIgnored: true, expectedMatched: true},
+		{path: "build", isDir: false, expectedIgnored: false, expectedMatched: false},
+		{path: "api/api.pb.go", expectedIgnored: true, expectedMatched: true},
+		{path: "api/keep.pb.go", expectedIgnored: false, expectedMatched: true},
+		{path: "pkg/foo/mocks", isDir: true, expectedIgnored: true, expectedMatched: true},
+		{path: "pkg/mocks", isDir: true, expectedIgnored: true, expectedMatched: true},
+		{path: "mocks", isDir: true, expectedIgnored: false, expectedMatched: false},
+		{path: "#hash.go", expectedIgnored: true, expectedMatched: true},
+		{path: "trailing.go", expectedIgnored: true, expectedMatched: true},
+		{path: "main.go", expectedIgnored: false, expectedMatched: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.path, func(t *testing.T) {
+			ignored, matched := g.Match(filepath.Join(dir, filepath.FromSlash(tt.path)), tt.isDir)
+			assert.Equal(t, tt.expectedIgnored, ignored)
+			assert.Equal(t, tt.expectedMatched, matched)
+		})
+	}
+
+	ignored, matched := g.Match(filepath.Join(filepath.Dir(dir), "api.pb.go"), false)
+	assert.False(t, ignored)
+	assert.False(t, matched)
+}
@@ -0,0 +1,124 @@
+package traverser
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filter selects the files to be processed by the include and exclude globs.
+// Globs are matched against the slash separated path relative to the base directory.
+// Globs without a slash match the name of the file or directory at any level, `**` matches any number of directories.
+// E.g. "zz_generated*.go", "pkg/**/*.go" or "vendor".
+type Filter struct {
+	// Base is the directory globs are relative to
+	Base string
+	// Include selects the files to be processed, all of them if empty
+	Include []string
+	// Exclude skips the files and directories, taking precedence over Include
+	Exclude []string
+	// All disables skipping hidden, vendor, testdata, git-ignored and generated files and directories by default
+	All bool
+}
+
+// Excluded reports whether the file or directory, or any of its parent directories, is matched by some of the exclude globs.
+func (f Filter) Excluded(p string) bool {
+	if len(f.Exclude) == 0 {
+		return false
+	}
+
+	rel, ok := f.rel(p)
+
+	return ok && excluded(f.Exclude, rel)
+}
+
+// Included reports whether the file is matched by some of the include globs and not excluded.
+func (f Filter) Included(p string) bool {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return true
+	}
+
+	rel, ok := f.rel(p)
+	if !ok {
+		return len(f.Include) == 0
+	}
+
+	return (len(f.Include) == 0 || matchAny(f.Include, rel)) && !excluded(f.Exclude, rel)
+}
+
+// rel returns the slash separated path relative to the base directory, false if the path is outside of it.
+func (f Filter) rel(p string) (string, bool) {
+	base, err := filepath.Abs(f.Base)
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// matchAny reports whether the relative path is matched by some of the globs.
+func matchAny(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if MatchGlob(glob, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excluded reports whether the relative path or any of its parent directories is matched by some of the globs.
+func excluded(globs []string, rel string) bool {
+	for p := rel; p != "."; p = path.Dir(p) {
+		if matchAny(globs, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchGlob reports whether the slash separated relative path is matched by the glob.
+// Malformed globs match nothing.
+func MatchGlob(glob, rel string) bool {
+	glob = strings.TrimSuffix(strings.TrimPrefix(glob, "./"), "/")
+	if !strings.Contains(glob, "/") {
+		// Match the name at any level
+		glob = "**/" + glob
+	}
+
+	return matchSegments(strings.Split(glob, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches the path segments by the glob segments.
+func matchSegments(glob, segments []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(glob[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(glob[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		glob, segments = glob[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -0,0 +1,55 @@
+package traverser_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/traverser"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob     string
+		rel      string
+		expected bool
+	}{
+		{glob: "*.go", rel: "main.go", expected: true},
+		{glob: "*.go", rel: "pkg/foo/foo.go", expected: true},
+		{glob: "zz_generated*.go", rel: "pkg/zz_generated.deepcopy.go", expected: true},
+		{glob: "vendor", rel: "vendor", expected: true},
+		{glob: "vendor/", rel: "pkg/vendor", expected: true},
+		{glob: "pkg/*.go", rel: "pkg/foo.go", expected: true},
+		{glob: "pkg/*.go", rel: "pkg/foo/foo.go", expected: false},
+		{glob: "pkg/**/*.go", rel: "pkg/foo.go", expected: true},
+		{glob: "pkg/**/*.go", rel: "pkg/foo/bar/foo.go", expected: true},
+		{glob: "./pkg/**", rel: "pkg/foo/foo.go", expected: true},
+		{glob: "pkg/**", rel: "cmd/foo.go", expected: false},
+		{glob: "pkg/[", rel: "pkg/foo.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.glob+" "+tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.expected, traverser.MatchGlob(tt.glob, tt.rel))
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	base := t.TempDir()
+	filter := traverser.Filter{
+		Base:    base,
+		Include: []string{"pkg/**"},
+		Exclude: []string{"*_test.go", "pkg/legacy"},
+	}
+
+	assert.True(t, filter.Included(filepath.Join(base, "pkg", "foo", "foo.go")))
+	assert.False(t, filter.Included(filepath.Join(base, "pkg", "foo", "foo_test.go")))
+	assert.False(t, filter.Included(filepath.Join(base, "cmd", "main.go")))
+	assert.False(t, filter.Included(filepath.Join(base, "pkg", "legacy", "foo.go")))
+	assert.True(t, filter.Excluded(filepath.Join(base, "pkg", "legacy")))
+	assert.False(t, filter.Excluded(filepath.Join(base, "pkg")))
+	assert.True(t, traverser.Filter{}.Included(filepath.Join(base, "main.go")))
+}
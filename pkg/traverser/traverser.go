@@ -2,73 +2,407 @@ package traverser
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
+	"mig/pkg/diff"
 	"mig/pkg/migrator"
+	"mig/pkg/migrator/causes"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/imports"
+	"mig/pkg/migrator/resolver"
+	"mig/pkg/util"
+	"mig/pkg/verifier"
 )
 
-func TraverseAndModifyFiles(root string, handlers migrator.MigrationHandlers) {
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// Options configure the traversal.
+type Options struct {
+	// DryRun disables writing the files back. Instead, unified diff of every changed file
+	// is printed to stdout, while progress is reported to stderr.
+	DryRun bool
+	// Verify type-checks the packages of the migrated files before saving them.
+	// Files introducing new type errors are reverted, i.e. left as they are.
+	Verify bool
+	// Filter selects the files to be migrated
+	Filter Filter
+	// Workers is the number of files processed in parallel, GOMAXPROCS if not positive
+	Workers int
+	// Log receives the progress, stdout (stderr in dry run mode) if nil
+	Log io.Writer
+	// Backup keeps the original content of every changed file next to it, with BackupSuffix appended to the name
+	Backup bool
+	// BackupDir is the directory the original content of every changed file is saved to, keeping the path
+	// relative to the root. It should be outside of the root or hidden, so the backups are not traversed.
+	BackupDir string
+	// Journal records the changes of the files before they are saved, if set
+	Journal Journal
+}
 
-		if info.IsDir() {
-			if strings.HasPrefix(info.Name(), ".") {
-				return nil // Skip hidden directories
-			}
+// Journal records the changes of the files, so they can be undone.
+type Journal interface {
+	// Record records the change of the file from the original content to the migrated one
+	Record(path string, before, after []byte) error
+}
 
-			return nil // Skip nested directories
+// FileResult is the outcome of processing a single file.
+type FileResult struct {
+	Path   string
+	Status common.Status
+	// Calls are the handled invocations which are not Unchanged
+	Calls []common.Call
+	// Err is set if the file failed to be processed
+	Err error
+	// Reverted is set if the migration introduced type errors, which are listed in Diagnostics
+	Reverted    bool
+	Diagnostics []string
+}
+
+// change is the migrated content of the file to be saved.
+type change struct {
+	content  []byte
+	migrated []byte
+}
+
+// Count returns the number of results with the given status.
+func (r FileResult) Count(status common.Status) int {
+	count := 0
+	for _, call := range r.Calls {
+		if call.Status == status {
+			count++
 		}
+	}
+
+	return count
+}
 
-		if filepath.Ext(path) != ".go" {
-			return nil // Skip non-go files
+// TraverseAndModifyFiles migrates the Go files found under the root with the handlers.
+// Files are processed by a pool of workers, while the progress, the diffs and the results are reported
+// in the order the files are walked in, regardless of the order the workers finish in.
+func TraverseAndModifyFiles(root string, handlers migrator.MigrationHandlers, opts Options) ([]FileResult, error) {
+	log := opts.Log
+	if log == nil {
+		// Keep stdout clean for the diff in dry run mode
+		log = os.Stdout
+		if opts.DryRun {
+			log = os.Stderr
 		}
+	}
 
-		fmt.Printf("Processing file: %s ...", path)
-		file, err := os.Open(path)
-		if err != nil {
-			return err
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan job)
+	var skipped []Skip
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		index := 0
+		skipped, walkErr = WalkGoFiles(root, opts.Filter, func(path string) error {
+			jobs <- job{index: index, path: path}
+			index++
+			return nil
+		})
+	}()
+
+	outcomes := make(chan outcome)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				outcomes <- process(root, j, handlers, opts)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var results []FileResult
+	pending := map[int]change{} // changes to be verified by the result index
+	done := map[int]outcome{}   // outcomes waiting for the preceding files to be reported
+	for o := range outcomes {
+		done[o.index] = o
+		for {
+			next, ok := done[len(results)]
+			if !ok {
+				break
+			}
+			delete(done, len(results))
+
+			if next.change != nil {
+				pending[len(results)] = *next.change
+			}
+			fmt.Print(next.diff)
+			logResult(log, next.result)
+			results = append(results, next.result)
 		}
-		defer func() { _ = file.Close() }()
-
-		scanner := bufio.NewScanner(file)
-
-		result := strings.Builder{}
-		saveFile := false
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			for _, handler := range handlers {
-				modified := handler(line)
-				if modified != "" {
-					saveFile = true
-					line = modified
-					break
-				}
+	}
+
+	// The walk is over once all the outcomes are received
+	err := walkErr
+	if err != nil {
+		fmt.Fprintf(log, "error walking the path %v: %v\n", root, err)
+	}
+	logSkipped(log, skipped)
+
+	if len(pending) > 0 {
+		verifyAndSave(root, results, pending, opts, log)
+	}
+
+	return results, err
+}
+
+// job is a file to be processed by the workers, the index is the position of the file in the walk order.
+type job struct {
+	index int
+	path  string
+}
+
+// outcome is the processed file.
+type outcome struct {
+	index  int
+	result FileResult
+	// change is set if the change has to be verified before saving
+	change *change
+	// diff is the unified diff of the change in dry run mode
+	diff string
+}
+
+// process migrates the file and saves the change, unless it has to be verified first.
+func process(root string, j job, handlers migrator.MigrationHandlers, opts Options) outcome {
+	result, ch := processFile(j.path, handlers)
+	o := outcome{index: j.index, result: result}
+	switch {
+	case ch == nil:
+	case opts.Verify:
+		o.change = ch
+	case opts.DryRun:
+		o.diff = unifiedDiff(root, j.path, ch.content, ch.migrated)
+	default:
+		o.result.Err = saveFile(root, j.path, *ch, opts)
+	}
+
+	return o
+}
+
+// logResult reports the outcome of the file processing.
+func logResult(log io.Writer, result FileResult) {
+	fmt.Fprintf(log, "Processing file: %s ...", result.Path)
+	switch {
+	case result.Err != nil:
+		// Keep processing other files
+		fmt.Fprintf(log, " failed: %v\n", result.Err)
+	case result.Status == common.Unchanged:
+		fmt.Fprintf(log, " no changes\n")
+	case result.Status == common.NeedsManual:
+		fmt.Fprintf(log, " changed, %d migrated, %d to be migrated manually\n",
+			result.Count(common.Migrated), result.Count(common.NeedsManual))
+	default:
+		fmt.Fprintf(log, " changed, %d migrated\n", result.Count(common.Migrated))
+	}
+}
+
+// verifyAndSave type-checks the migrated files, reverts those introducing new type errors and saves the rest.
+func verifyAndSave(root string, results []FileResult, pending map[int]change, opts Options, log io.Writer) {
+	files := make(map[string][]byte, len(pending))
+	indices := make([]int, 0, len(pending))
+	for i, ch := range pending {
+		files[results[i].Path] = ch.migrated
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	fmt.Fprintf(log, "Verifying %d changed files ...\n", len(files))
+	diagnostics, err := verifier.Verify(files)
+	if err != nil {
+		fmt.Fprintf(log, "verification failed, no files are saved: %v\n", err)
+	}
+
+	for _, i := range indices {
+		result := &results[i]
+		switch {
+		case err != nil:
+			result.Err = fmt.Errorf("verification failed: %w", err)
+		case len(diagnostics[result.Path]) > 0:
+			result.Reverted = true
+			result.Diagnostics = diagnostics[result.Path]
+			fmt.Fprintf(log, "Reverting file: %s, the migration introduces type errors:\n", result.Path)
+			for _, d := range result.Diagnostics {
+				fmt.Fprintf(log, "\t%s\n", d)
+			}
+		default:
+			if result.Err = saveFile(root, result.Path, pending[i], opts); result.Err != nil {
+				fmt.Fprintf(log, "Saving file: %s failed: %v\n", result.Path, result.Err)
 			}
-			result.WriteString(line + "\n")
 		}
+	}
+}
 
-		if err := scanner.Err(); err != nil {
-			return err
+// processFile migrates the file and returns the change to be saved, if any.
+func processFile(path string, handlers migrator.MigrationHandlers) (FileResult, *change) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return FileResult{Path: path, Err: err}, nil
+	}
+
+	// Statements inspecting errors.Cause results are rewritten first, so the handlers don't see those invocations
+	pkgs := handlers.Packages.WithDefaults()
+	source, causeCalls, err := causes.Rewrite(content, pkgs.Source)
+	if err != nil {
+		return FileResult{Path: path, Err: err}, nil
+	}
+
+	var fileResult common.FileResult
+	if handlers.File != nil {
+		fileResult, err = handlers.File(source)
+	} else {
+		fileResult, err = modifyLines(source, pkgs.Source, handlers.Lines)
+	}
+	if err != nil {
+		return FileResult{Path: path, Err: err}, nil
+	}
+	if fileResult.Content == nil {
+		fileResult.Content = source
+	}
+	fileResult.Calls = append(causeCalls, fileResult.Calls...)
+
+	result := FileResult{Path: path, Status: fileResult.Status()}
+	for _, call := range fileResult.Calls {
+		if call.Status != common.Unchanged {
+			result.Calls = append(result.Calls, call)
 		}
+	}
 
-		if !saveFile {
-			fmt.Printf(" no changes\n")
-			return nil
+	if result.Status == common.Unchanged {
+		return result, nil
+	}
+
+	// Handlers rewrite the invocations only, so the imports have to be fixed up afterwards
+	migrated, err := imports.Fix(content, fileResult.Content, pkgs)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	migrated = keepLineEndings(content, migrated)
+
+	if bytes.Equal(content, migrated) {
+		return result, nil
+	}
+
+	return result, &change{content: content, migrated: migrated}
+}
+
+// saveFile saves the migrated content back to the file, backing up the original content and recording
+// the change in the journal if configured, or prints the diff in dry run mode.
+func saveFile(root, path string, ch change, opts Options) error {
+	if opts.DryRun {
+		fmt.Print(unifiedDiff(root, path, ch.content, ch.migrated))
+		return nil
+	}
+
+	if err := backupFile(root, path, ch.content, opts); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	if opts.Journal != nil {
+		if err := opts.Journal.Record(path, ch.content, ch.migrated); err != nil {
+			return fmt.Errorf("journal failed: %w", err)
 		}
+	}
+
+	// Now let's save the result back to file
+	return util.ReplaceFile(path, ch.migrated)
+}
 
-		fmt.Printf(" changed\n")
+// unifiedDiff returns unified diff between the file content and the result.
+// The file path is made relative to the root, so the diff can be applied with `git apply` within the root.
+// If the root is the file itself, its name is used.
+func unifiedDiff(root, path string, content, result []byte) string {
+	name := filepath.Base(path)
+	if rel, err := filepath.Rel(root, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
 
-		// Now let's save builder content back to file
-		return os.WriteFile(path, []byte(result.String()), 0644)
-	})
+	return diff.Unified(filepath.ToSlash(name), content, result)
+}
 
+// modifyLines applies handlers to every line of the file.
+// Lines of invocations of the package imported by the source path spanning multiple lines
+// are joined and passed to the handlers at once.
+func modifyLines(content []byte, source string, handlers []migrator.HandleLine) (common.FileResult, error) {
+	resolved, err := resolver.Resolve(content, source)
 	if err != nil {
-		fmt.Printf("error walking the path %v: %v\n", root, err)
+		return common.FileResult{}, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return common.FileResult{}, err
+	}
+
+	result := strings.Builder{}
+	var calls []common.Call
+
+	for number := 1; number <= len(lines); {
+		// Invocations started on the joined lines may span even further
+		last := number
+		for n := number; n <= last && n <= len(lines); n++ {
+			last = max(last, resolved.End(n))
+		}
+		last = min(last, len(lines))
+
+		line := strings.Join(lines[number-1:last], "\n")
+		for _, handler := range handlers {
+			r := handler(common.Line{Text: line, Number: number, File: resolved})
+			if r.Status != common.Unchanged {
+				calls = append(calls, lineCalls(number, line, r)...)
+				line = r.Text
+				break
+			}
+		}
+		result.WriteString(line + "\n")
+		number = last + 1
+	}
+
+	if common.MaxStatus(calls) == common.Unchanged {
+		return common.FileResult{}, nil
 	}
+
+	return common.FileResult{Content: []byte(result.String()), Calls: calls}, nil
+}
+
+// lineCalls returns the invocations handled within the line.
+// If the handler doesn't track separate invocations, the whole line is reported as one.
+func lineCalls(number int, line string, r common.Result) []common.Call {
+	if len(r.Calls) > 0 {
+		return r.Calls
+	}
+
+	trimmed := strings.TrimLeft(line, " \t")
+
+	return []common.Call{{
+		Line:      number,
+		Column:    len(line) - len(trimmed) + 1,
+		Original:  trimmed,
+		Rewritten: strings.TrimLeft(r.Text, " \t"),
+		Status:    r.Status,
+		Matcher:   r.Matcher,
+		Reason:    r.Reason,
+	}}
 }
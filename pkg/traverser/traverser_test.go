@@ -0,0 +1,291 @@
+package traverser_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/journal"
+	"mig/pkg/migrator"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/traverser"
+)
+
+const source = `package foo
+
+import "github.com/pkg/errors"
+
+func foo(err error, name string) error {
+	if err == nil {
+		return errors.New("Not found")
+	}
+	return errors.Wrapf(err, "Failed to get secret %s", name)
+}
+`
+
+const migrated = `package foo
+
+import "github.com/kanisterio/errkit"
+
+func foo(err error, name string) error {
+	if err == nil {
+		return errkit.New("Not found")
+	}
+	return errkit.Wrap(err, "Failed to get secret", "secret", name)
+}
+`
+
+// writeTree writes the number of packages with a few files each under the root, returning the paths in the walk order.
+func writeTree(t testing.TB, root string, packages int) []string {
+	var paths []string
+	for i := 0; i < packages; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%03d", i))
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		for _, name := range []string{"a.go", "b.go", "c.go"} {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, os.WriteFile(path, []byte(source), 0644))
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func handlers(t testing.TB, version migrator.MigratorVersion) migrator.MigrationHandlers {
+	h, err := migrator.GetMigratorHandlers(version, common.DefaultConfig)
+	assert.NoError(t, err)
+
+	return h
+}
+
+func TestTraverseAndModifyFiles(t *testing.T) {
+	for _, workers := range []int{1, 8} {
+		workers := workers // Capture range variable
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			root := t.TempDir()
+			paths := writeTree(t, root, 10)
+
+			log := bytes.Buffer{}
+			results, err := traverser.TraverseAndModifyFiles(root, handlers(t, migrator.V2), traverser.Options{Workers: workers, Log: &log})
+			assert.NoError(t, err)
+
+			expectedLog := bytes.Buffer{}
+			if assert.Len(t, results, len(paths)) {
+				for i, path := range paths {
+					assert.Equal(t, path, results[i].Path)
+					assert.Equal(t, common.Migrated, results[i].Status)
+					fmt.Fprintf(&expectedLog, "Processing file: %s ... changed, 3 migrated\n", path)
+
+					content, err := os.ReadFile(path)
+					assert.NoError(t, err)
+					assert.Equal(t, migrated, string(content))
+				}
+			}
+			assert.Equal(t, expectedLog.String(), log.String())
+		})
+	}
+}
+
+func TestTraverseAndModifyFilesV1StandardErrors(t *testing.T) {
+	const std = `package foo
+
+import "errors"
+
+func foo() error {
+	return errors.New("std")
+}
+`
+
+	root := t.TempDir()
+	stdPath, pkgPath := filepath.Join(root, "std.go"), filepath.Join(root, "pkg.go")
+	assert.NoError(t, os.WriteFile(stdPath, []byte(std), 0644))
+	assert.NoError(t, os.WriteFile(pkgPath, []byte(source), 0644))
+
+	_, err := traverser.TraverseAndModifyFiles(root, handlers(t, migrator.V1), traverser.Options{Log: io.Discard})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(stdPath)
+	assert.NoError(t, err)
+	assert.Equal(t, std, string(content))
+
+	content, err = os.ReadFile(pkgPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `errkit.New("Not found")`)
+}
+
+func TestTraverseAndModifyFilesWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		migrated string
+	}{
+		{
+			name:     "LF",
+			source:   source,
+			migrated: migrated,
+		},
+		{
+			name:     "CRLF",
+			source:   strings.ReplaceAll(source, "\n", "\r\n"),
+			migrated: strings.ReplaceAll(migrated, "\n", "\r\n"),
+		},
+		{
+			name:     "No trailing newline",
+			source:   strings.TrimSuffix(source, "\n"),
+			migrated: strings.TrimSuffix(migrated, "\n"),
+		},
+		{
+			name:     "CRLF without trailing newline",
+			source:   strings.TrimSuffix(strings.ReplaceAll(source, "\n", "\r\n"), "\r\n"),
+			migrated: strings.TrimSuffix(strings.ReplaceAll(migrated, "\n", "\r\n"), "\r\n"),
+		},
+		{
+			name:     "Mixed",
+			source:   strings.NewReplacer("foo\n", "foo\r\n", "name)\n", "name)\r\n", "{\n", "{\r\n").Replace(source),
+			migrated: strings.NewReplacer("foo\n", "foo\r\n", "name)\n", "name)\r\n", "{\n", "{\r\n").Replace(migrated),
+		},
+		{
+			name:     "Mixed with CRLF trailing newline",
+			source:   strings.TrimSuffix(source, "\n") + "\r\n",
+			migrated: strings.TrimSuffix(migrated, "\n") + "\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		for _, version := range []migrator.MigratorVersion{migrator.V2, migrator.V3} {
+			t.Run(fmt.Sprintf("%s/%s", tt.name, version), func(t *testing.T) {
+				root := t.TempDir()
+				path := filepath.Join(root, "pkg", "foo.go")
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				assert.NoError(t, os.WriteFile(path, []byte(tt.source), 0600))
+
+				backupDir := filepath.Join(t.TempDir(), "backup")
+				opts := traverser.Options{Log: io.Discard, Backup: true, BackupDir: backupDir}
+				_, err := traverser.TraverseAndModifyFiles(root, handlers(t, version), opts)
+				assert.NoError(t, err)
+
+				content, err := os.ReadFile(path)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.migrated, string(content))
+
+				info, err := os.Stat(path)
+				assert.NoError(t, err)
+				assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+				for _, backup := range []string{path + traverser.BackupSuffix, filepath.Join(backupDir, "pkg", "foo.go")} {
+					content, err := os.ReadFile(backup)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.source, string(content))
+				}
+
+				// No temporary files are left behind
+				entries, err := os.ReadDir(filepath.Dir(path))
+				assert.NoError(t, err)
+				assert.Len(t, entries, 2)
+			})
+		}
+	}
+}
+
+func TestTraverseAndModifyFilesSymlink(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(t.TempDir(), "foo.go")
+	assert.NoError(t, os.WriteFile(target, []byte(source), 0644))
+	link := filepath.Join(root, "foo.go")
+	assert.NoError(t, os.Symlink(target, link))
+
+	_, err := traverser.TraverseAndModifyFiles(link, handlers(t, migrator.V2), traverser.Options{Log: io.Discard})
+	assert.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+
+	content, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, migrated, string(content))
+}
+
+func TestTraverseAndModifyFilesJournal(t *testing.T) {
+	root := t.TempDir()
+	paths := writeTree(t, root, 2)
+
+	dir := t.TempDir()
+	recorder, err := journal.Begin(dir, root)
+	assert.NoError(t, err)
+	_, err = traverser.TraverseAndModifyFiles(root, handlers(t, migrator.V2), traverser.Options{Log: io.Discard, Journal: recorder})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Close())
+	assert.Equal(t, len(paths), recorder.Len())
+
+	outcomes, err := journal.Undo(dir, recorder.ID())
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, len(paths))
+	for _, o := range outcomes {
+		assert.Equal(t, journal.Restored, o.Status)
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, source, string(content))
+	}
+}
+
+func BenchmarkTraverseAndModifyFiles(b *testing.B) {
+	for _, version := range []migrator.MigratorVersion{migrator.V2, migrator.V3} {
+		for _, workers := range []int{1, 4, 0} {
+			name := fmt.Sprintf("%s/%d workers", version, workers)
+			if workers == 0 {
+				name = fmt.Sprintf("%s/GOMAXPROCS workers", version)
+			}
+			b.Run(name, func(b *testing.B) {
+				h := handlers(b, version)
+				opts := traverser.Options{Workers: workers, Log: io.Discard}
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					root := b.TempDir()
+					writeTree(b, root, 50)
+					b.StartTimer()
+
+					if _, err := traverser.TraverseAndModifyFiles(root, h, opts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTraverseAndModifyFilesDryRun(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pkg", "foo.go")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(source), 0644))
+
+	// The diff is printed to stdout
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	os.Stdout = w
+	_, err = traverser.TraverseAndModifyFiles(root, handlers(t, migrator.V2), traverser.Options{DryRun: true, Log: io.Discard})
+	os.Stdout = stdout
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	// Paths are relative to the root even if it's outside of the working directory
+	assert.True(t, strings.HasPrefix(string(out), "--- a/pkg/foo.go\n+++ b/pkg/foo.go\n"), string(out))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(content))
+}
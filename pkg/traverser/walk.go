@@ -0,0 +1,219 @@
+package traverser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SkipReason is the reason a file or directory is not processed.
+type SkipReason string
+
+const (
+	SkipHidden      SkipReason = "hidden"
+	SkipVendor      SkipReason = "vendor"
+	SkipTestdata    SkipReason = "testdata"
+	SkipIgnored     SkipReason = "ignored by .gitignore"
+	SkipGenerated   SkipReason = "generated"
+	SkipExcluded    SkipReason = "excluded"
+	SkipNotIncluded SkipReason = "not included"
+)
+
+// skipReasons are the reasons in the order the summary reports them
+var skipReasons = []SkipReason{SkipHidden, SkipVendor, SkipTestdata, SkipIgnored, SkipGenerated, SkipExcluded, SkipNotIncluded}
+
+// Skip is a file or directory which is not processed.
+type Skip struct {
+	Path   string
+	Reason SkipReason
+	Dir    bool
+}
+
+// Matches the comment marking generated files, see https://go.dev/s/generatedcode
+var generatedRegex = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// WalkGoFiles calls fn for every Go file found under the root and selected by the filter,
+// and returns the files and directories skipped on the way.
+// Unless the filter selects all the files, hidden directories and files, `vendor` and `testdata` directories,
+// paths ignored by `.gitignore` files and generated files are skipped.
+// The root itself is never skipped by these defaults.
+func WalkGoFiles(root string, filter Filter, fn func(path string) error) ([]Skip, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignores ignoreChain
+	if !filter.All {
+		if ignores, err = rootIgnoreChain(root); err != nil {
+			return nil, err
+		}
+	}
+
+	var skipped []Skip
+	skip := func(path string, reason SkipReason, dir bool) {
+		skipped = append(skipped, Skip{Path: path, Reason: reason, Dir: dir})
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		abs := absRoot
+		if path != root {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			abs = filepath.Join(absRoot, rel)
+		}
+
+		if info.IsDir() {
+			if path != root {
+				if info.Name() == ".git" && !filter.All {
+					return filepath.SkipDir // Not worth reporting
+				}
+				if reason := defaultSkip(info, abs, ignores, filter); reason != "" {
+					skip(path, reason, true)
+					return filepath.SkipDir
+				}
+				if filter.Excluded(path) {
+					skip(path, SkipExcluded, true)
+					return filepath.SkipDir
+				}
+			}
+
+			if !filter.All {
+				if ignores, err = ignores.enter(abs); err != nil {
+					return err
+				}
+			}
+
+			return nil // Walk into the directory
+		}
+
+		if filepath.Ext(path) != ".go" {
+			return nil // Skip non-go files
+		}
+
+		if path != root {
+			if reason := defaultSkip(info, abs, ignores, filter); reason != "" {
+				skip(path, reason, false)
+				return nil
+			}
+		}
+
+		if !filter.Included(path) {
+			if filter.Excluded(path) {
+				skip(path, SkipExcluded, false)
+			} else {
+				skip(path, SkipNotIncluded, false)
+			}
+			return nil
+		}
+
+		if path != root && !filter.All {
+			generated, err := isGenerated(path)
+			if err != nil {
+				return err
+			}
+			if generated {
+				skip(path, SkipGenerated, false)
+				return nil
+			}
+		}
+
+		return fn(path)
+	})
+
+	return skipped, err
+}
+
+// defaultSkip returns the reason the file or directory is skipped by default, or empty string if it's not.
+func defaultSkip(info os.FileInfo, abs string, ignores ignoreChain, filter Filter) SkipReason {
+	if filter.All {
+		return ""
+	}
+
+	name := info.Name()
+	switch {
+	case strings.HasPrefix(name, "."):
+		return SkipHidden
+	case info.IsDir() && name == "vendor":
+		return SkipVendor
+	case info.IsDir() && name == "testdata":
+		return SkipTestdata
+	case ignores.Ignored(abs, info.IsDir()):
+		return SkipIgnored
+	}
+
+	return ""
+}
+
+// isGenerated reports whether the Go file is marked as generated, i.e. has the `// Code generated ... DO NOT EDIT.`
+// comment before the package clause.
+func isGenerated(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	inComment := false
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		switch {
+		case inComment:
+			inComment = !bytes.Contains(line, []byte("*/"))
+		case generatedRegex.Match(line):
+			return true, nil
+		case len(line) == 0 || bytes.HasPrefix(line, []byte("//")):
+		case bytes.HasPrefix(line, []byte("/*")):
+			inComment = !bytes.Contains(line[2:], []byte("*/"))
+		default:
+			// The package clause or other code ends the header
+			return false, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// logSkipped reports the skipped files and directories by the reason.
+func logSkipped(log io.Writer, skipped []Skip) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	counts := map[SkipReason]int{}
+	for _, s := range skipped {
+		counts[s.Reason]++
+	}
+	var summary []string
+	for _, reason := range skipReasons {
+		if counts[reason] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[reason], reason))
+		}
+	}
+	fmt.Fprintf(log, "Skipped %d files and directories: %s\n", len(skipped), strings.Join(summary, ", "))
+
+	for _, reason := range skipReasons {
+		for _, s := range skipped {
+			if s.Reason != reason {
+				continue
+			}
+			kind := "file"
+			if s.Dir {
+				kind = "directory"
+			}
+			fmt.Fprintf(log, "\t%s %s: %s\n", kind, s.Path, s.Reason)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package traverser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/traverser"
+)
+
+const generated = `// Copyright 2024
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package foo
+`
+
+const notGenerated = `/*
+Copyright 2024
+*/
+
+package foo
+
+// Code generated by controller-gen. DO NOT EDIT.
+`
+
+// writeFiles writes the files by the slash separated paths relative to the root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestWalkGoFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/info/exclude":       "local.go\n",
+		".gitignore":              "build/\n*.pb.go\n",
+		".hidden/foo.go":          source,
+		".foo.go":                 source,
+		"main.go":                 source,
+		"local.go":                source,
+		"README.md":               "",
+		"vendor/foo/foo.go":       source,
+		"pkg/testdata/foo.go":     source,
+		"pkg/foo/foo.go":          source,
+		"pkg/foo/foo.pb.go":       source,
+		"pkg/foo/zz_generated.go": generated,
+		"pkg/foo/header.go":       notGenerated,
+		"pkg/bar/.gitignore":      "!*.pb.go\nbar.go\n",
+		"pkg/bar/bar.go":          source,
+		"pkg/bar/bar.pb.go":       source,
+		"pkg/baz/baz.go":          source,
+		"build/foo.go":            source,
+	})
+
+	tests := []struct {
+		name            string
+		filter          traverser.Filter
+		expectedPaths   []string
+		expectedSkipped []traverser.Skip
+	}{
+		{
+			name:   "Default",
+			filter: traverser.Filter{Base: root, Exclude: []string{"pkg/baz"}},
+			expectedPaths: []string{
+				"main.go",
+				"pkg/bar/bar.pb.go",
+				"pkg/foo/foo.go",
+				"pkg/foo/header.go",
+			},
+			expectedSkipped: []traverser.Skip{
+				{Path: ".foo.go", Reason: traverser.SkipHidden},
+				{Path: ".hidden", Reason: traverser.SkipHidden, Dir: true},
+				{Path: "build", Reason: traverser.SkipIgnored, Dir: true},
+				{Path: "local.go", Reason: traverser.SkipIgnored},
+				{Path: "pkg/bar/bar.go", Reason: traverser.SkipIgnored},
+				{Path: "pkg/baz", Reason: traverser.SkipExcluded, Dir: true},
+				{Path: "pkg/foo/foo.pb.go", Reason: traverser.SkipIgnored},
+				{Path: "pkg/foo/zz_generated.go", Reason: traverser.SkipGenerated},
+				{Path: "pkg/testdata", Reason: traverser.SkipTestdata, Dir: true},
+				{Path: "vendor", Reason: traverser.SkipVendor, Dir: true},
+			},
+		},
+		{
+			name:   "Include",
+			filter: traverser.Filter{Base: root, Include: []string{"pkg/foo/*.go"}},
+			expectedPaths: []string{
+				"pkg/foo/foo.go",
+				"pkg/foo/header.go",
+			},
+			expectedSkipped: []traverser.Skip{
+				{Path: ".foo.go", Reason: traverser.SkipHidden},
+				{Path: ".hidden", Reason: traverser.SkipHidden, Dir: true},
+				{Path: "build", Reason: traverser.SkipIgnored, Dir: true},
+				{Path: "local.go", Reason: traverser.SkipIgnored},
+				{Path: "main.go", Reason: traverser.SkipNotIncluded},
+				{Path: "pkg/bar/bar.go", Reason: traverser.SkipIgnored},
+				{Path: "pkg/bar/bar.pb.go", Reason: traverser.SkipNotIncluded},
+				{Path: "pkg/baz/baz.go", Reason: traverser.SkipNotIncluded},
+				{Path: "pkg/foo/foo.pb.go", Reason: traverser.SkipIgnored},
+				{Path: "pkg/foo/zz_generated.go", Reason: traverser.SkipGenerated},
+				{Path: "pkg/testdata", Reason: traverser.SkipTestdata, Dir: true},
+				{Path: "vendor", Reason: traverser.SkipVendor, Dir: true},
+			},
+		},
+		{
+			name:   "All",
+			filter: traverser.Filter{Base: root, Exclude: []string{".git"}, All: true},
+			expectedPaths: []string{
+				".foo.go",
+				".hidden/foo.go",
+				"build/foo.go",
+				"local.go",
+				"main.go",
+				"pkg/bar/bar.go",
+				"pkg/bar/bar.pb.go",
+				"pkg/baz/baz.go",
+				"pkg/foo/foo.go",
+				"pkg/foo/foo.pb.go",
+				"pkg/foo/header.go",
+				"pkg/foo/zz_generated.go",
+				"pkg/testdata/foo.go",
+				"vendor/foo/foo.go",
+			},
+			expectedSkipped: []traverser.Skip{
+				{Path: ".git", Reason: traverser.SkipExcluded, Dir: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			skipped, err := traverser.WalkGoFiles(root, tt.filter, func(path string) error {
+				rel, err := filepath.Rel(root, path)
+				paths = append(paths, filepath.ToSlash(rel))
+				return err
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPaths, paths)
+
+			for i := range skipped {
+				rel, err := filepath.Rel(root, skipped[i].Path)
+				assert.NoError(t, err)
+				skipped[i].Path = filepath.ToSlash(rel)
+			}
+			assert.Equal(t, tt.expectedSkipped, skipped)
+		})
+	}
+}
+
+func TestWalkGoFilesRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "vendor")
+	writeFiles(t, root, map[string]string{
+		"zz_generated.go": generated,
+	})
+
+	var paths []string
+	skipped, err := traverser.WalkGoFiles(root, traverser.Filter{}, func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+	assert.Equal(t, []traverser.Skip{{Path: filepath.Join(root, "zz_generated.go"), Reason: traverser.SkipGenerated}}, skipped)
+
+	file := filepath.Join(root, "zz_generated.go")
+	paths = nil
+	skipped, err = traverser.WalkGoFiles(file, traverser.Filter{}, func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{file}, paths)
+	assert.Empty(t, skipped)
+}
@@ -0,0 +1,153 @@
+package traverser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"mig/pkg/util"
+)
+
+// BackupSuffix is appended to the path of the original file backed up next to it
+const BackupSuffix = ".orig"
+
+var (
+	crlf = []byte("\r\n")
+	lf   = []byte("\n")
+)
+
+// lookahead is the number of the original lines searched for the migrated one when the line endings are mixed
+const lookahead = 100
+
+// keepLineEndings returns the migrated content with the line endings and the trailing newline of the original one.
+// Handlers, as well as gofmt, produce LF line endings and a trailing newline regardless of the original content.
+func keepLineEndings(original, migrated []byte) []byte {
+	crlfs := bytes.Count(original, crlf)
+	switch {
+	case crlfs == 0:
+	case crlfs == bytes.Count(original, lf):
+		migrated = bytes.ReplaceAll(bytes.ReplaceAll(migrated, crlf, lf), lf, crlf)
+	default:
+		migrated = restoreLineEndings(original, migrated)
+	}
+
+	switch newline := lineEnding(original); {
+	case len(original) == 0:
+	case !bytes.HasSuffix(original, lf):
+		for _, suffix := range [][]byte{crlf, lf} {
+			migrated = bytes.TrimSuffix(migrated, suffix)
+		}
+	case !bytes.HasSuffix(migrated, lf):
+		migrated = append(migrated, newline...)
+	}
+
+	return migrated
+}
+
+// restoreLineEndings returns the migrated content with each line ending like the original line it comes from.
+// Unchanged lines are found among the following original lines and keep their own ending, while changed or added
+// lines take the ending of the original line they replace.
+func restoreLineEndings(original, migrated []byte) []byte {
+	var texts []string
+	var endings [][]byte
+	for _, line := range bytes.SplitAfter(original, lf) {
+		text, ending := splitLineEnding(line)
+		texts = append(texts, string(text))
+		endings = append(endings, ending)
+	}
+
+	result := make([]byte, 0, len(migrated)+len(migrated)/16)
+	next := 0
+	for _, line := range bytes.SplitAfter(migrated, lf) {
+		text, ending := splitLineEnding(line)
+		result = append(result, text...)
+		if len(ending) == 0 {
+			continue
+		}
+
+		ending = endings[min(next, len(endings)-1)]
+		for i := next; i < min(next+lookahead, len(texts)); i++ {
+			if texts[i] == string(text) {
+				ending, next = endings[i], i+1
+				break
+			}
+		}
+		if len(ending) == 0 {
+			// The original line has no ending, being the last one
+			ending = lineEnding(original)
+		}
+		result = append(result, ending...)
+	}
+
+	return result
+}
+
+// splitLineEnding splits the line into the text and the line ending, which is empty for the last line
+// without a trailing newline.
+func splitLineEnding(line []byte) ([]byte, []byte) {
+	for _, ending := range [][]byte{crlf, lf} {
+		if bytes.HasSuffix(line, ending) {
+			return line[:len(line)-len(ending)], ending
+		}
+	}
+
+	return line, nil
+}
+
+// lineEnding returns the line ending used by the content: the one of the last line if the endings are mixed.
+func lineEnding(content []byte) []byte {
+	if idx := bytes.LastIndexByte(content, '\n'); idx > 0 && content[idx-1] == '\r' {
+		return crlf
+	}
+
+	return lf
+}
+
+// backupFile saves the original content of the file before it's overwritten, next to the file with BackupSuffix
+// if Backup is set, and under BackupDir, keeping the path relative to the root, if BackupDir is set.
+// Existing backups are overwritten, so they hold the content preceding the last write.
+func backupFile(root, path string, content []byte, opts Options) error {
+	if !opts.Backup && opts.BackupDir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	perm := info.Mode().Perm()
+
+	if opts.Backup {
+		if err := util.WriteFileAtomically(path+BackupSuffix, content, perm); err != nil {
+			return err
+		}
+	}
+
+	if opts.BackupDir != "" {
+		dest := filepath.Join(opts.BackupDir, backupPath(root, path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := util.WriteFileAtomically(dest, content, perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupPath returns the path of the file relative to the root directory, or to the directory
+// of the root if it is the file itself.
+func backupPath(root, path string) string {
+	base := root
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		base = filepath.Dir(root)
+	}
+
+	rel, err := filepath.Rel(base, path)
+	if err != nil || !within(base, path) {
+		return filepath.Base(path)
+	}
+
+	return rel
+}
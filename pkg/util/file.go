@@ -0,0 +1,60 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ReplaceFile atomically replaces the content of the file, keeping its mode. The content is written to a temporary
+// file in the same directory first, which is then renamed over the file, so the file is never left half-written.
+// Symbolic links are followed, so the file they point to is replaced rather than the link.
+func ReplaceFile(path string, content []byte) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomically(target, content, info.Mode().Perm())
+}
+
+// WriteFileAtomically writes the content to the temporary file which is then renamed to the path.
+// The temporary file is hidden, so it's skipped by the migration if left behind by a killed process.
+func WriteFileAtomically(path string, content []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = write(tmp, content, perm)
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// write writes the content to the file, sets its permissions and closes it.
+func write(file *os.File, content []byte, perm os.FileMode) error {
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Chmod(perm); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -50,12 +50,16 @@ func removeBrackets(s string) string {
 	return s
 }
 
+var (
+	// Match the name preceding `with ID` or `ID`, e.g. "volume with ID" or "volume ID"
+	nameWithIDRegex = regexp.MustCompile(`(.*)\s+(.*)\s+with\s+ID`)
+	nameIDRegex     = regexp.MustCompile(`(.*)\s+(.*)\s+ID`)
+)
+
 func tryNameWithID(s string) string {
-	regex1 := regexp.MustCompile(`(.*)\s+(.*)\s+with\s+ID`)
-	match := regex1.FindStringSubmatch(s)
+	match := nameWithIDRegex.FindStringSubmatch(s)
 	if match == nil {
-		regex2 := regexp.MustCompile(`(.*)\s+(.*)\s+ID`)
-		match = regex2.FindStringSubmatch(s)
+		match = nameIDRegex.FindStringSubmatch(s)
 	}
 
 	if match == nil {
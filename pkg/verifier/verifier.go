@@ -0,0 +1,141 @@
+package verifier
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// Verify type-checks the packages containing the files twice, as they are on disk and with the migrated content,
+// and returns the type errors introduced by the migration per file. Files without new errors are omitted.
+// Packages are loaded offline, against the module cache and the vendor directory, the files on disk are not modified.
+// New errors found in a file which wasn't migrated are attributed to every migrated file of its package.
+func Verify(files map[string][]byte) (map[string][]string, error) {
+	// go/packages reports absolute paths, so keep track of the paths the files were given by
+	paths := map[string]string{}
+	dirs := map[string][]string{}
+	for path := range files {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		paths[abs] = path
+		dirs[filepath.Dir(abs)] = append(dirs[filepath.Dir(abs)], abs)
+	}
+
+	result := map[string][]string{}
+	for dir, migrated := range dirs {
+		before, err := typeErrors(dir, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		overlay := map[string][]byte{}
+		for _, abs := range migrated {
+			overlay[abs] = files[paths[abs]]
+		}
+		after, err := typeErrors(dir, overlay)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range introduced(before, after) {
+			if path, ok := paths[e.file]; ok {
+				result[path] = append(result[path], e.text)
+				continue
+			}
+
+			for _, abs := range migrated {
+				result[paths[abs]] = append(result[paths[abs]], e.text)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// typeError is an error reported for the package.
+type typeError struct {
+	file string // absolute path of the file the error is reported at, empty if unknown
+	msg  string
+	text string // the error with its position
+}
+
+// typeErrors loads the package in the directory, along with its tests, and returns all errors reported for it.
+func typeErrors(dir string, overlay map[string][]byte) ([]typeError, error) {
+	cfg := &packages.Config{
+		Mode:    loadMode,
+		Dir:     dir,
+		Env:     append(os.Environ(), "GOPROXY=off"),
+		Tests:   true,
+		Overlay: overlay,
+	}
+
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	// Dependencies are the same before and after the migration, so only the packages of the directory are checked
+	var errs []typeError
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			// Errors without position duplicate the positioned ones, e.g. the compiler output of the export data build
+			file := errorFile(e.Pos)
+			if file == "" || file == "-" {
+				continue
+			}
+			errs = append(errs, typeError{file: file, msg: e.Msg, text: e.Error()})
+		}
+	}
+
+	return errs, nil
+}
+
+// introduced returns the errors reported after the migration only.
+// Errors are matched by the file and the message, as the migration may shift the lines.
+func introduced(before, after []typeError) []typeError {
+	seen := map[[2]string]int{}
+	for _, e := range before {
+		seen[[2]string{e.file, e.msg}]++
+	}
+
+	var result []typeError
+	reported := map[string]bool{}
+	for _, e := range after {
+		key := [2]string{e.file, e.msg}
+		if seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+
+		// The package and its test variant report the same errors
+		if !reported[e.text] {
+			reported[e.text] = true
+			result = append(result, e)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].text < result[j].text })
+
+	return result
+}
+
+// errorFile returns the file of the `file:line:column` error position.
+func errorFile(pos string) string {
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(pos, ":")
+		if idx == -1 || strings.Trim(pos[idx+1:], "0123456789") != "" {
+			break
+		}
+		pos = pos[:idx]
+	}
+
+	return pos
+}
@@ -0,0 +1,42 @@
+package verifier_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mig/pkg/verifier"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	write("go.mod", "module example.com/foo\n\ngo 1.22\n")
+	broken := write("broken.go", "package foo\n\nfunc broken() int {\n\treturn 1\n}\n")
+	fine := write("fine.go", "package foo\n\nfunc fine() int {\n\treturn 1\n}\n")
+	// Errors existing before the migration are not reported
+	write("existing.go", "package foo\n\nfunc existing() int {\n\treturn undefined\n}\n")
+
+	diagnostics, err := verifier.Verify(map[string][]byte{
+		broken: []byte("package foo\n\nfunc broken() int {\n\n\treturn \"1\"\n}\n"),
+		fine:   []byte("package foo\n\nfunc fine() int {\n\treturn 2\n}\n"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, diagnostics, 1)
+	if assert.Len(t, diagnostics[broken], 1) {
+		assert.True(t, strings.HasPrefix(diagnostics[broken][0], broken+":5:9: "), diagnostics[broken][0])
+	}
+
+	// Files on disk are kept as is
+	content, err := os.ReadFile(broken)
+	assert.NoError(t, err)
+	assert.Equal(t, "package foo\n\nfunc broken() int {\n\treturn 1\n}\n", string(content))
+}
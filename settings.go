@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mig/pkg/config"
+	"mig/pkg/journal"
+	"mig/pkg/migrator"
+	common "mig/pkg/migrator/common"
+	"mig/pkg/migrator/rules"
+	"mig/pkg/traverser"
+)
+
+// settings are the migration settings of the run, taken from the project configuration and overridden by the flags.
+type settings struct {
+	engine     migrator.MigratorVersion
+	cfg        common.Config
+	filter     traverser.Filter
+	reportPath string
+	sarifPath  string
+	backup     bool
+	backupDir  string
+	// journalDir is the directory of the run journals, empty if the journal is disabled
+	journalDir string
+}
+
+// flagValues are the values of the command line flags configuring the run.
+type flagValues struct {
+	configPath string
+	engine     string
+	include    string
+	exclude    string
+	todo       string
+	noTodo     bool
+	all        bool
+	rulesPath  string
+	source     string
+	target     string
+	qualifier  string
+	reportPath string
+	sarifPath  string
+	backup     bool
+	backupDir  string
+	journalDir string
+	noJournal  bool
+}
+
+// loadSettings loads the project configuration applying to the target path, unless given explicitly,
+// and overrides it with the flags set on the command line.
+func loadSettings(target string, flags flagValues) (settings, error) {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var project *config.Config
+	var err error
+	if flags.configPath != "" {
+		project, err = config.Load(flags.configPath)
+	} else {
+		project, err = config.Discover(target)
+	}
+	if err != nil {
+		return settings{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if project == nil {
+		project = &config.Config{}
+	}
+
+	s := settings{
+		engine:     migrator.V2,
+		cfg:        common.DefaultConfig,
+		filter:     traverser.Filter{Base: project.Dir, Include: project.Include, Exclude: project.Exclude},
+		reportPath: project.Path(project.Output.Report),
+		sarifPath:  project.Path(project.Output.SARIF),
+		backup:     project.Backup,
+		backupDir:  project.Path(project.BackupDir),
+		journalDir: project.Path(project.Journal),
+	}
+	if project.Engine != "" {
+		s.engine = project.Engine
+	}
+	if project.Todo != nil {
+		s.cfg.TodoComment = *project.Todo
+	}
+	s.cfg.FieldNames = project.FieldNames
+	rulesPath := project.Path(project.Rules)
+	s.cfg.Packages = common.Packages{
+		Source:    project.Imports.Source,
+		Target:    project.Imports.Target,
+		Qualifier: project.Imports.Qualifier,
+	}
+
+	// Globs given on the command line are relative to the target directory
+	if set["include"] || set["exclude"] {
+		s.filter = traverser.Filter{Base: targetDir(target), Include: splitGlobs(flags.include), Exclude: splitGlobs(flags.exclude)}
+	}
+	s.filter.All = project.All
+	if set["all"] {
+		s.filter.All = flags.all
+	}
+	if set["engine"] {
+		s.engine = migrator.MigratorVersion(flags.engine)
+	}
+	if set["todo"] {
+		s.cfg.TodoComment = flags.todo
+	}
+	if flags.noTodo {
+		s.cfg.TodoComment = ""
+	}
+	if set["source"] {
+		s.cfg.Packages.Source = flags.source
+	}
+	if set["target"] {
+		s.cfg.Packages.Target = flags.target
+	}
+	if set["qualifier"] {
+		s.cfg.Packages.Qualifier = flags.qualifier
+	}
+	if set["rules"] {
+		rulesPath = flags.rulesPath
+	}
+	if set["report"] {
+		s.reportPath = flags.reportPath
+	}
+	if set["sarif"] {
+		s.sarifPath = flags.sarifPath
+	}
+	if set["backup"] {
+		s.backup = flags.backup
+	}
+	if set["backup-dir"] {
+		s.backupDir = flags.backupDir
+	}
+	if set["journal"] {
+		s.journalDir = flags.journalDir
+	}
+	if s.journalDir == "" {
+		if s.journalDir, err = journal.DefaultDir(); err != nil {
+			return settings{}, fmt.Errorf("failed to locate the journal directory: %w", err)
+		}
+	}
+	if flags.noJournal {
+		s.journalDir = ""
+	}
+
+	if rulesPath != "" {
+		s.cfg.Matchers, err = rules.LoadMatchers(rulesPath)
+		if err != nil {
+			return settings{}, fmt.Errorf("failed to load rules: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// targetDir returns the directory of the target path.
+func targetDir(target string) string {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return filepath.Dir(target)
+	}
+
+	return target
+}
+
+// splitGlobs splits the comma separated list of globs.
+func splitGlobs(list string) []string {
+	var globs []string
+	for _, glob := range strings.Split(list, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+
+	return globs
+}
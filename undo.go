@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"mig/pkg/config"
+	"mig/pkg/journal"
+)
+
+// runUndo restores the files changed by the run given by the ID, or lists the recorded runs if no ID is given,
+// and returns the exit code.
+func runUndo(args []string) int {
+	flags := flag.NewFlagSet("undo", flag.ExitOnError)
+	journalDir := flags.String("journal", "", "directory the run journals are kept in, the one of the project configuration "+
+		"or the user cache directory by default")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: myapp undo [-journal dir] [run-id]")
+		fmt.Fprintln(flags.Output(), "Restores the files changed by the run, except those edited after the run. Lists the runs if no ID is given.")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	dir, err := undoJournalDir(*journalDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	if flags.NArg() == 0 {
+		return listRuns(dir)
+	}
+
+	outcomes, err := journal.Undo(dir, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	code := exitOK
+	for _, o := range outcomes {
+		switch o.Status {
+		case journal.Failed:
+			fmt.Printf("Restoring file: %s ... failed: %v\n", o.Path, o.Err)
+			code = exitFailure
+		case journal.Modified, journal.Missing:
+			fmt.Printf("Restoring file: %s ... refused, %s\n", o.Path, o.Status)
+			code = exitFailure
+		default:
+			fmt.Printf("Restoring file: %s ... %s\n", o.Path, o.Status)
+		}
+	}
+
+	return code
+}
+
+// undoJournalDir returns the directory of the run journals, the flag value taking precedence
+// over the project configuration of the working directory.
+func undoJournalDir(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	project, err := config.Discover(".")
+	if err != nil {
+		return "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if project != nil && project.Journal != "" {
+		return project.Path(project.Journal), nil
+	}
+
+	dir, err := journal.DefaultDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the journal directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// listRuns prints the runs recorded in the directory, the latest first.
+func listRuns(dir string) int {
+	journals, err := journal.List(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	if len(journals) == 0 {
+		fmt.Printf("No runs recorded in %s\n", dir)
+	}
+	for _, j := range journals {
+		fmt.Printf("%s\t%s\t%d files\t%s\n", j.ID, j.Time.Local().Format("2006-01-02 15:04:05"), len(j.Files), j.Root)
+	}
+
+	return exitOK
+}